@@ -5,54 +5,158 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Constants
 const DefaultMinLenght int = 12
 const DefaultMaxLenght int = 20
+const MaxPassLength int = 1 << 20
 const VersionString string = "0.3.2"
 
+// Password generation algorithms
+const AlgoPronounceable int = 0
+const AlgoRandom int = 1
+const AlgoPassphrase int = 2
+const AlgoPattern int = 3
+const AlgoKoremutake int = 4
+
 type Config struct {
-	minPassLen    int
-	maxPassLen    int
-	numOfPass     int
-	useComplex    bool
-	useLowerCase  bool
-	useUpperCase  bool
-	useNumber     bool
-	useSpecial    bool
-	humanReadable bool
-	checkHibp     bool
-	excludeChars  string
-	newStyleModes string
-	spellPassword bool
-	ShowHelp      bool
-	showVersion   bool
-	outputMode    int
+	minPassLen        int
+	maxPassLen        int
+	numOfPass         int
+	lengthDist        string
+	maxRepeat         int
+	maxSequence       int
+	maxKeyboardWalk   int
+	useComplex        bool
+	useLowerCase      bool
+	useUpperCase      bool
+	useNumber         bool
+	useSpecial        bool
+	humanReadable     bool
+	layoutSafe        bool
+	requireAllModes   bool
+	ambiguousChars    string
+	specialCharSet    string
+	checkHibp         bool
+	showEntropy       bool
+	minEntropy        float64
+	excludeChars      string
+	customChars       string
+	newStyleModes     string
+	spellPassword     bool
+	showSyllables     bool
+	phoneticAlphabet  string
+	cryptPassword     bool
+	ShowHelp          bool
+	showVersion       bool
+	outputMode        int
+	pwAlgo            int
+	wordListFile      string
+	numOfWords        int
+	wordSeparator     string
+	separatorSet      string
+	injectChars       bool
+	groupLength       int
+	pwPattern         string
+	blockedSubstrings []string
+	contextStrings    []string
+	syllableTemplates []string
+	uniquePasswords   bool
+	firstCharModes    string
+	lastCharModes     string
+	trimUnsafeChars   string
+	balancedClasses   bool
 }
 
 // Help text
 const usage = `apg-go // A "Automated Password Generator"-clone
 Copyright (c) 2021 Winni Neessen
 
-apg [-m <length>] [-x <length>] [-L] [-U] [-N] [-S] [-H] [-C]
-    [-l] [-M mode] [-E char_string] [-n num_of_pass] [-v] [-h]
+apg [-a <algo>] [-m <length>] [-x <length>] [-d dist] [-L] [-U] [-N] [-S] [-z set] [-H]
+    [-A <chars>] [-Y] [-k] [-B] [-F sets] [-J sets] [-I chars] [-C] [-T templates] [-l] [-o]
+    [-t alphabet] [-M mode] [-E char_string] [-c char_string] [-R num] [-Q num] [-K num]
+    [-n num_of_pass] [-D] [-r wordlist_file] [-W num_of_words] [-s separator] [-w separators]
+    [-i] [-g length] [-P pattern] [-X substrings] [-u context] [-y] [-e] [-b bits]
+    [-v] [-h]
 
 Options:
-    -m LENGTH            Minimum length of the password to be generated (Default: 12)
-    -x LENGTH            Maximum length of the password to be generated (Default: 20)
+    -a ALGORITHM         Choose the password generation algorithm by number or name (Default: 1)
+                         - 0/pronounceable: pronounceable password generation (similar to FIPS-181)
+                         - 1/random: random password generation
+                         - 2/passphrase: passphrase generation (requires -r)
+                         - 3/pattern: pattern based password generation (requires -P)
+                         - 4/koremutake: koremutake password generation (-m/-x set the amount of syllables)
+    -m LENGTH            Minimum length of the password to be generated (Default: 12, Maximum: 1048576)
+    -x LENGTH            Maximum length of the password to be generated (Default: 20, Maximum: 1048576)
+    -d DISTRIBUTION      Distribution of the password length between -m and -x (Default: uniform)
+                         - uniform: every length is equally likely
+                         - prefermax: longer passwords are more likely (the longer of two random lengths)
+                         - prefermin: shorter passwords are more likely (the shorter of two random lengths)
     -n NUMBER            Amount of password to be generated (Default: 6)
+    -D                   Make sure that all passwords generated in this run are distinct (all passwords are kept
+                         in memory, so the memory usage grows with -n) (Default: off)
     -E CHARS             List of characters to be excluded in the generated password
+    -c CHARS             List of custom characters to be added to the character set
     -M [LUNSHClunshc]    New style password parameters (upper case: on, lower case: off)
+    -R NUMBER            Maximum amount of identical characters in a row (Default: 0 = unlimited)
+    -Q NUMBER            Maximum length of character sequences like abc or 321 (Default: 0 = unlimited)
+    -K NUMBER            Maximum length of keyboard walks like qwer or 1qaz on QWERTY, QWERTZ and AZERTY
+                         keyboards (Default: 0 = unlimited)
     -L                   Use lower case characters in passwords (Default: on)
     -U                   Use upper case characters in passwords (Default: on)
     -N                   Use numeric characters in passwords (Default: on)
     -S                   Use special characters in passwords (Default: off)
+    -z SET               Set of special characters used by -S (Default: default)
+                         - default: all printable ASCII special characters
+                         - urlsafe: characters that are safe in URLs without encoding (-._~)
+                         - shellsafe: characters that are safe in POSIX shells without quoting (%+,-./:=@_)
     -H                   Avoid ambiguous characters in passwords (i. e.: 1, l, I, O, 0) (Default: off)
+    -A CHARS             List of characters that are considered ambiguous by -H (Default: 0, 1, I, L, O, i, l,
+                         o and all special characters besides "#%*+-/:;=\_|~)
+    -Y                   Only use characters that are at the same key on QWERTY, QWERTZ and AZERTY keyboards
+                         (b-l, n-p, r-v and x in lower and upper case, requires -M with n and s) (Default: off)
+    -k                   Require at least one character of every enabled character set (random passwords
+                         only) (Default: off)
+    -B                   Use every enabled character set equally often (random passwords only, implies -k)
+                         (Default: off)
+    -F SETS              Character sets allowed for the first character (random passwords only): any of
+                         L = lower case, U = upper case, N = numeric, S = special (Default: all)
+    -J SETS              Character sets allowed for the last character (random passwords only), see -F
+                         (Default: all)
+    -I CHARS             List of characters that passwords must not start or end with, as many applications
+                         strip them from their input (Default: all whitespace characters)
     -C                   Enable complex password mode (implies -L -U -N -S and disables -H) (Default: off)
+    -r FILE              Word list file for passphrase generation (one word per line)
+    -W NUMBER            Amount of words in a generated passphrase (Default: 6)
+    -s SEPARATOR         Separator between the words of a generated passphrase or the groups of -g (Default: -)
+    -w CHARS             Randomly select the separator between every two words of a generated passphrase
+                         from CHARS (replaces -s) (Default: off)
+    -i                   Inject a random number (-N) and/or special character (-S) between the words of a
+                         generated passphrase (Default: off)
+    -g LENGTH            Print generated passwords in groups of LENGTH characters (i. e.: 4fj2-9dkq-1mz8)
+                         '--> the hash (-y) and the HIBP check (-p) use the ungrouped password (Default: 0 = off)
+    -P PATTERN           Pattern for pattern based password generation: ?l = lower case, ?u = upper case,
+                         ?d = numeric, ?s = special, ?a = any, ?? = '?', other characters are used literally
+    -X SUBSTRINGS        Comma-separated list of substrings that generated passwords must not contain
+                         (case-insensitive, can be used multiple times)
+    -u CONTEXT           User name, e-mail address or service name that generated passwords must not
+                         contain, even with l33t substitutions (can be used multiple times)
     -l                   Spell generated passwords in phonetic alphabet (Default: off)
+    -T TEMPLATES         Comma-separated list of syllable templates for pronounceable passwords made of
+                         C (consonant) and V (vowel) tokens (Default: CV,VC,CVC)
+    -o                   Show the syllables of pronounceable passwords (i. e.: ka-ti-bo-4-ze) (Default: off)
+    -t ALPHABET          Phonetic alphabet for the password spelling (Default: nato)
+                         - nato: NATO/ICAO alphabet (Alfa, Bravo, Charlie, ...)
+                         - din5009: German DIN 5009:2022 alphabet (Aachen, Berlin, Chemnitz, ...)
+                         - jan: Joint Army/Navy alphabet (Able, Baker, Charlie, ...)
+    -y                   Print the SHA512-crypt hash of each generated password (Default: off)
     -p                   Check the HIBP database if the generated passwords was found in a leak before (Default: off)
                          '--> this feature requires internet connectivity 
+    -e                   Show the entropy of the generated passwords and the average time to crack them
+                         (Default: off)
+    -b BITS              Minimum entropy (in bits) the generated passwords need to provide (Default: 0 = off)
     -h                   Show this help text
     -v                   Show version string`
 
@@ -73,40 +177,205 @@ func main() {
 	}
 
 	// Set PW length and available characterset
+	if err := validateCharRange(&config); err != nil {
+		log.Fatalf("invalid character set: %v", err)
+	}
 	charRange := getCharRange(&config)
+	charClasses := getCharClasses(&config)
+	var pwEdges edgeRanges
+	if config.pwAlgo == AlgoRandom {
+		pwEdges, _ = getEdgeRanges(&config)
+	}
+
+	// Read the word list for passphrase generation
+	var wordList []string
+	if config.pwAlgo == AlgoPassphrase {
+		var err error
+		wordList, err = readWordList(config.wordListFile)
+		if err != nil {
+			log.Fatalf("unable to read word list: %v", err)
+		}
+	}
+
+	// Parse the password pattern
+	var patternRanges []string
+	if config.pwAlgo == AlgoPattern {
+		var err error
+		patternRanges, err = parsePattern(&config)
+		if err != nil {
+			log.Fatalf("unable to parse password pattern: %v", err)
+		}
+	}
+
+	// Make sure the passwords to be generated provide the minimum entropy
+	if err := applyMinEntropy(&config, charRange, wordList); err != nil {
+		log.Fatalf("unable to meet minimum entropy: %v", err)
+	}
+
+	// Make sure enough distinct passwords can be generated
+	if config.uniquePasswords {
+		if err := validateKeyspace(&config, charRange, wordList); err != nil {
+			log.Fatalf("unable to generate distinct passwords: %v", err)
+		}
+	}
+
+	// Show the entropy of the passwords to be generated
+	if config.showEntropy {
+		entropy, err := getEntropy(&config, charRange, wordList)
+		if err != nil {
+			log.Printf("unable to calculate entropy: %v", err)
+		} else {
+			entropyBound := "worst case"
+			if hasUncountedLimits(&config) {
+				entropyBound = "upper bound, -R and -Q are not taken into account"
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Entropy of generated passwords: %.2f bits (%s)\n", entropy,
+				entropyBound)
+			for _, curProfile := range attackerProfiles {
+				_, _ = fmt.Fprintf(os.Stderr, "Average time to crack with an %s: %s\n", curProfile.profileName,
+					formatCrackTime(getCrackTime(entropy, curProfile.guessesPerSecond)))
+			}
+		}
+	}
+
+	// Select the phonetic alphabet for the password spelling
+	letterNames, err := getPhoneticAlphabet(config.phoneticAlphabet)
+	if err != nil {
+		log.Fatalf("invalid phonetic alphabet: %v", err)
+	}
+
+	// Keep track of the generated passwords if they need to be distinct
+	var seenPasswords map[string]bool
+	if config.uniquePasswords {
+		seenPasswords = make(map[string]bool)
+	}
 
 	// Generate passwords
 	for i := 1; i <= config.numOfPass; i++ {
-		pwLength := getPwLengthFromParams(&config)
-		pwString, err := getRandChar(&charRange, pwLength)
-		if err != nil {
-			log.Fatalf("getRandChar returned an error: %q\n", err)
+		var pwString string
+		var pwSyllables []string
+		var err error
+		var isPwned bool
+		numOfHibpChecks := 0
+		// Regenerate passwords that contain a blocked substring, a context string or a keyboard walk,
+		// that exceed the limit of identical characters in a row or of character sequences, that start
+		// or end with a word or syllable that is not trim-safe and that have already been generated.
+		// Passwords that were found in the HIBP database are regenerated up to MaxHibpAttempts times
+		for attempt := 1; ; attempt++ {
+			switch config.pwAlgo {
+			case AlgoPronounceable:
+				pwSyllables, err = getPronounceableSyllables(&config, getPwLengthFromParams(&config))
+				if err != nil {
+					log.Fatalf("getPronounceableSyllables returned an error: %q\n", err)
+				}
+				pwString = strings.Join(pwSyllables, "")
+			case AlgoPassphrase:
+				pwString, err = getPassphrase(&config, wordList)
+				if err != nil {
+					log.Fatalf("getPassphrase returned an error: %q\n", err)
+				}
+			case AlgoKoremutake:
+				pwString, err = getKoremutakePassword(getPwLengthFromParams(&config))
+				if err != nil {
+					log.Fatalf("getKoremutakePassword returned an error: %q\n", err)
+				}
+			case AlgoPattern:
+				pwString, err = getPatternPassword(patternRanges)
+				if err != nil {
+					log.Fatalf("getPatternPassword returned an error: %q\n", err)
+				}
+			default:
+				if config.balancedClasses {
+					pwString, err = getRandCharBalanced(getPwLengthFromParams(&config), config.maxRepeat,
+						config.maxSequence, charClasses, pwEdges)
+					if err != nil {
+						log.Fatalf("getRandCharBalanced returned an error: %q\n", err)
+					}
+					break
+				}
+				if config.requireAllModes {
+					pwString, err = getRandCharAllClasses(&charRange, getPwLengthFromParams(&config),
+						config.maxRepeat, config.maxSequence, charClasses, pwEdges)
+					if err != nil {
+						log.Fatalf("getRandCharAllClasses returned an error: %q\n", err)
+					}
+					break
+				}
+				pwString, err = getRandCharLimited(&charRange, getPwLengthFromParams(&config), config.maxRepeat,
+					config.maxSequence, pwEdges)
+				if err != nil {
+					log.Fatalf("getRandCharLimited returned an error: %q\n", err)
+				}
+			}
+
+			if !containsBlockedSubstring(pwString, config.blockedSubstrings) &&
+				!containsContextString(pwString, config.contextStrings) &&
+				!hasKeyboardWalk(pwString, config.maxKeyboardWalk) &&
+				!hasCharLimitViolation([]rune(pwString), config.maxRepeat, config.maxSequence) &&
+				!seenPasswords[pwString] && hasTrimSafeEdges(pwString, config.trimUnsafeChars) {
+				if !config.checkHibp || numOfHibpChecks >= MaxHibpAttempts {
+					break
+				}
+				numOfHibpChecks++
+				isPwned, err = checkHibp(pwString)
+				if err != nil {
+					log.Printf("unable to check HIBP database: %v", err)
+					break
+				}
+				if !isPwned {
+					break
+				}
+			}
+			if attempt >= MaxGenerationAttempts {
+				log.Fatalf("unable to generate a password that meets all requirements after %d attempts", attempt)
+			}
+		}
+		if seenPasswords != nil {
+			seenPasswords[pwString] = true
+		}
+
+		var cryptHash string
+		if config.cryptPassword {
+			cryptHash, err = getCryptHash(pwString)
+			if err != nil {
+				log.Fatalf("getCryptHash returned an error: %q\n", err)
+			}
+			cryptHash = " " + cryptHash
+		}
+
+		pwDisplay := groupPasswordString(pwString, config.groupLength, config.wordSeparator)
+		if config.showSyllables && pwSyllables != nil {
+			pwDisplay = pwDisplay + " (" + strings.Join(pwSyllables, "-") + ")"
 		}
 
 		switch config.outputMode {
 		case 1:
 			{
-				spelledPw, err := spellPasswordString(pwString)
+				spelledPw, err := spellPasswordString(pwString, letterNames)
 				if err != nil {
 					log.Fatalf("spellPasswordString returned an error: %q\n", err.Error())
 				}
-				fmt.Printf("%v (%v)\n", pwString, spelledPw)
+				_, err = fmt.Printf("%v (%v)%v\n", pwDisplay, spelledPw, cryptHash)
+				if err != nil {
+					log.Fatalf("unable to write password after %d passwords: %v", i-1, err)
+				}
 				break
 			}
 		default:
 			{
-				fmt.Println(pwString)
+				_, err = fmt.Printf("%v%v\n", pwDisplay, cryptHash)
+				if err != nil {
+					log.Fatalf("unable to write password after %d passwords: %v", i-1, err)
+				}
 				break
 			}
 		}
 
-		if config.checkHibp {
-			isPwned, err := checkHibp(pwString)
+		if isPwned {
+			_, err = fmt.Print("^-- !!WARNING: The previously generated password was found in HIPB database. " +
+				"Do not use it!!\n")
 			if err != nil {
-				log.Printf("unable to check HIBP database: %v", err)
-			}
-			if isPwned {
-				fmt.Print("^-- !!WARNING: The previously generated password was found in HIPB database. Do not use it!!\n")
+				log.Fatalf("unable to write HIBP warning after %d passwords: %v", i, err)
 			}
 		}
 	}
@@ -1,7 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode"
+	"unicode/utf8"
 )
 
 var config Config
@@ -53,6 +67,303 @@ func TestGetRandNum(t *testing.T) {
 	}
 }
 
+// Test getRandNumBetween with different ranges
+func TestGetRandNumBetween(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		minNum     int
+		maxNum     int
+		shouldFail bool
+	}{
+		{"randNum between 8 and 20", 8, 20, false},
+		{"randNum between 5 and 5", 5, 5, false},
+		{"randNum between -10 and -5", -10, -5, false},
+		{"randNum between -5 and 5", -5, 5, false},
+		{"randNum should fail on min > max", 20, 8, true},
+		{"randNum should fail on overflowing range", -int(^uint(0)>>1) - 1, int(^uint(0) >> 1), true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				randNum, err := getRandNumBetween(testCase.minNum, testCase.maxNum)
+				if testCase.shouldFail {
+					if err == nil {
+						t.Fatalf("Random number generation succeeded but was expected to fail. Returned: %v",
+							randNum)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Random number generation failed: %v", err)
+				}
+				if randNum < testCase.minNum || randNum > testCase.maxNum {
+					t.Fatalf("Random number generation returned value out of range. Expected %v to %v, got: %v",
+						testCase.minNum, testCase.maxNum, randNum)
+				}
+			}
+		})
+	}
+}
+
+// Test shuffleRunes
+func TestShuffleRunes(t *testing.T) {
+	t.Run("shuffle_keeps_all_chars", func(t *testing.T) {
+		runeSlice := []rune("abcdefghij")
+		if err := shuffleRunes(runeSlice); err != nil {
+			t.Fatalf("Shuffling characters failed: %v", err)
+		}
+		if len(runeSlice) != 10 {
+			t.Fatalf("Shuffling characters changed the amount of characters: %v", len(runeSlice))
+		}
+		for _, curChar := range "abcdefghij" {
+			if !strings.ContainsRune(string(runeSlice), curChar) {
+				t.Fatalf("Shuffled characters %q are missing character %q", string(runeSlice), curChar)
+			}
+		}
+	})
+
+	t.Run("all_positions_are_used", func(t *testing.T) {
+		posCount := make(map[int]int)
+		for i := 0; i < 1000; i++ {
+			runeSlice := []rune("abcd")
+			if err := shuffleRunes(runeSlice); err != nil {
+				t.Fatalf("Shuffling characters failed: %v", err)
+			}
+			posCount[strings.IndexRune(string(runeSlice), 'a')]++
+		}
+		for i := 0; i < 4; i++ {
+			if posCount[i] == 0 {
+				t.Fatalf("Shuffling never moved character to position %d", i)
+			}
+		}
+	})
+
+	t.Run("shuffle_fails_on_random_source_error", func(t *testing.T) {
+		defer func(origReader io.Reader) { randReader = origReader }(randReader)
+		randReader = failReader{}
+		if err := shuffleRunes([]rune("abcd")); !errors.Is(err, errFailReader) {
+			t.Fatalf("Shuffling was expected to fail with random source error, got: %v", err)
+		}
+	})
+}
+
+// Test getRandNum with a custom source of randomness
+func TestGetRandNumRandReader(t *testing.T) {
+	defer func(origReader io.Reader) { randReader = origReader }(randReader)
+
+	t.Run("deterministic_reader", func(t *testing.T) {
+		randReader = bytes.NewReader([]byte{5})
+		randNum, err := getRandNum(10)
+		if err != nil {
+			t.Fatalf("Random number generation failed: %v", err)
+		}
+		if randNum != 5 {
+			t.Errorf("Random number generation with deterministic reader returned wrong value. "+
+				"Expected: 5, got: %v", randNum)
+		}
+	})
+
+	t.Run("chunking_reader", func(t *testing.T) {
+		randReader = iotest.OneByteReader(bytes.NewReader([]byte{1, 2}))
+		randNum, err := getRandNum(1 << 16)
+		if err != nil {
+			t.Fatalf("Random number generation with chunking reader failed: %v", err)
+		}
+		if randNum != 258 {
+			t.Errorf("Random number generation with chunking reader returned wrong value. "+
+				"Expected: 258, got: %v", randNum)
+		}
+	})
+
+	t.Run("short_reader", func(t *testing.T) {
+		randReader = bytes.NewReader([]byte{1})
+		randNum, err := getRandNum(1 << 16)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Random number generation with short reader was expected to fail, got %v, %v",
+				randNum, err)
+		}
+	})
+
+	t.Run("failing_reader", func(t *testing.T) {
+		randReader = failReader{}
+		randNum, err := getRandNum(10)
+		if !errors.Is(err, errFailReader) {
+			t.Errorf("Random number generation with failing reader was expected to fail, got %v, %v",
+				randNum, err)
+		}
+	})
+
+	t.Run("nil_reader", func(t *testing.T) {
+		randReader = nil
+		randNum, err := getRandNum(10)
+		if err == nil {
+			t.Errorf("Random number generation with nil reader was expected to fail, got %v", randNum)
+		}
+	})
+}
+
+// Test that errors of the source of randomness are returned by all generation algorithms
+func TestRandReaderErrors(t *testing.T) {
+	defer func(origReader io.Reader) { randReader = origReader }(randReader)
+	randReader = failReader{}
+
+	testConfig := Config{
+		useLowerCase:  true,
+		useUpperCase:  true,
+		useNumber:     true,
+		numOfWords:    4,
+		wordSeparator: "-",
+	}
+	charRange := getCharRange(&testConfig)
+	if _, err := getRandChar(&charRange, 10); !errors.Is(err, errFailReader) {
+		t.Errorf("getRandChar was expected to fail with random source error, got: %v", err)
+	}
+	if _, err := getPronounceableSyllables(&testConfig, 10); !errors.Is(err, errFailReader) {
+		t.Errorf("getPronounceableSyllables was expected to fail with random source error, got: %v", err)
+	}
+	if _, err := getPassphrase(&testConfig, []string{"alpha", "bravo"}); !errors.Is(err, errFailReader) {
+		t.Errorf("getPassphrase was expected to fail with random source error, got: %v", err)
+	}
+}
+
+// Test validateConfig() with different config settings
+func TestValidateConfig(t *testing.T) {
+	testTable := []struct {
+		testName string
+		config   Config
+		expErr   error
+	}{
+		{"valid_random", Config{useLowerCase: true, minPassLen: 12, maxPassLen: 20, pwAlgo: AlgoRandom}, nil},
+		{"valid_min_greater_than_max", Config{useNumber: true, minPassLen: 20, maxPassLen: 12, pwAlgo: AlgoRandom},
+			nil},
+		{"valid_custom_chars_only", Config{customChars: "abc", pwAlgo: AlgoRandom}, nil},
+		{"valid_passphrase", Config{useLowerCase: true, pwAlgo: AlgoPassphrase, numOfWords: 6}, nil},
+		{"no_modes_set", Config{pwAlgo: AlgoRandom}, ErrNoModesSet},
+		{"negative_min_length", Config{useLowerCase: true, minPassLen: -1, maxPassLen: 20}, ErrInvalidLength},
+		{"negative_max_length", Config{useLowerCase: true, maxPassLen: -1}, ErrInvalidLength},
+		{"max_supported_length", Config{useLowerCase: true, minPassLen: MaxPassLength, maxPassLen: MaxPassLength},
+			nil},
+		{"min_length_too_large", Config{useLowerCase: true, minPassLen: MaxPassLength + 1}, ErrLengthTooLarge},
+		{"max_length_too_large", Config{useLowerCase: true, maxPassLen: MaxPassLength + 1}, ErrLengthTooLarge},
+		{"words_too_large", Config{useLowerCase: true, pwAlgo: AlgoPassphrase, numOfWords: MaxPassLength + 1},
+			ErrLengthTooLarge},
+		{"require_all_modes", Config{useLowerCase: true, useNumber: true, requireAllModes: true, minPassLen: 2,
+			pwAlgo: AlgoRandom}, nil},
+		{"require_all_modes_too_short", Config{useLowerCase: true, useNumber: true, requireAllModes: true,
+			minPassLen: 1, pwAlgo: AlgoRandom}, ErrInvalidLength},
+		{"edge_chars", Config{useLowerCase: true, useNumber: true, firstCharModes: "l", lastCharModes: "LN",
+			minPassLen: 1, pwAlgo: AlgoRandom}, nil},
+		{"edge_chars_not_enabled", Config{useLowerCase: true, firstCharModes: "N", minPassLen: 12,
+			pwAlgo: AlgoRandom}, ErrNoEdgeChars},
+		{"edge_chars_single_char", Config{useLowerCase: true, useNumber: true, firstCharModes: "L",
+			lastCharModes: "N", minPassLen: 1, pwAlgo: AlgoRandom}, ErrNoEdgeChars},
+		{"edge_chars_no_overlap", Config{useLowerCase: true, useNumber: true, firstCharModes: "L",
+			lastCharModes: "N", minPassLen: 2, pwAlgo: AlgoRandom}, nil},
+		{"require_all_modes_edge_chars", Config{useLowerCase: true, useNumber: true, requireAllModes: true,
+			firstCharModes: "N", lastCharModes: "N", minPassLen: 3, maxPassLen: 3, pwAlgo: AlgoRandom}, nil},
+		{"require_all_modes_no_edge_chars", Config{useLowerCase: true, useNumber: true, requireAllModes: true,
+			firstCharModes: "N", lastCharModes: "N", minPassLen: 2, maxPassLen: 2, pwAlgo: AlgoRandom},
+			ErrNoEdgeChars},
+		{"require_all_modes_no_edge_chars_range", Config{useLowerCase: true, useNumber: true,
+			requireAllModes: true, firstCharModes: "N", lastCharModes: "N", minPassLen: 2, maxPassLen: 20,
+			pwAlgo: AlgoRandom}, ErrNoEdgeChars},
+		{"balanced_edge_chars", Config{useLowerCase: true, useNumber: true, balancedClasses: true,
+			firstCharModes: "N", lastCharModes: "N", minPassLen: 3, maxPassLen: 3, pwAlgo: AlgoRandom}, nil},
+		{"balanced_no_edge_chars", Config{useLowerCase: true, useNumber: true, balancedClasses: true,
+			firstCharModes: "N", lastCharModes: "N", minPassLen: 2, maxPassLen: 2, pwAlgo: AlgoRandom},
+			ErrNoEdgeChars},
+		{"control_char_custom_chars", Config{customChars: "ab\tc", pwAlgo: AlgoRandom}, ErrControlChar},
+		{"control_char_custom_chars_newline", Config{customChars: "ab\r\n", pwAlgo: AlgoRandom}, ErrControlChar},
+		{"control_char_separator", Config{useLowerCase: true, pwAlgo: AlgoPassphrase, numOfWords: 6,
+			wordSeparator: "\n"}, ErrControlChar},
+		{"control_char_separator_set", Config{useLowerCase: true, pwAlgo: AlgoPassphrase, numOfWords: 6,
+			separatorSet: "-\x7f"}, ErrControlChar},
+		{"control_char_pattern", Config{useLowerCase: true, pwAlgo: AlgoPattern, pwPattern: "?l\x00?d"},
+			ErrControlChar},
+		{"unicode_custom_chars", Config{customChars: "äöü€", pwAlgo: AlgoRandom}, nil},
+		{"whitespace_custom_chars_only", Config{customChars: " \u3000", trimUnsafeChars: DefaultTrimUnsafeChars,
+			pwAlgo: AlgoRandom}, ErrNoTrimSafeChars},
+		{"whitespace_custom_chars", Config{useNumber: true, customChars: " ", trimUnsafeChars: DefaultTrimUnsafeChars,
+			pwAlgo: AlgoRandom}, nil},
+		{"whitespace_custom_chars_allowed", Config{customChars: " ", pwAlgo: AlgoRandom}, nil},
+		{"single_char_no_trim_safe_chars", Config{useNumber: true, useSpecial: true, specialCharSet: "urlsafe",
+			firstCharModes: "S", trimUnsafeChars: "-._~", minPassLen: 1, pwAlgo: AlgoRandom}, ErrNoTrimSafeChars},
+		{"whitespace_pattern_edge", Config{useLowerCase: true, pwPattern: "?d\u3000",
+			trimUnsafeChars: DefaultTrimUnsafeChars, pwAlgo: AlgoPattern}, ErrNoTrimSafeChars},
+		{"negative_keyboard_walk", Config{useLowerCase: true, maxKeyboardWalk: -1}, ErrInvalidLength},
+		{"negative_group_length", Config{useLowerCase: true, groupLength: -1}, ErrInvalidLength},
+		{"negative_min_entropy", Config{useLowerCase: true, minEntropy: -1}, ErrInvalidEntropy},
+		{"pronounceable_no_letters", Config{useNumber: true, pwAlgo: AlgoPronounceable}, ErrNoSyllableChars},
+		{"pronounceable_no_vowels", Config{useLowerCase: true, excludeChars: PronVowels,
+			pwAlgo: AlgoPronounceable}, ErrNoSyllableChars},
+		{"pronounceable_vowel_templates_only", Config{useLowerCase: true, excludeChars: PronConsonants,
+			syllableTemplates: []string{"V", "VV"}, pwAlgo: AlgoPronounceable}, nil},
+		{"passphrase_zero_words", Config{useLowerCase: true, pwAlgo: AlgoPassphrase}, ErrInvalidLength},
+		{"passphrase_single_word_no_trim_safe_chars", Config{useNumber: true, pwAlgo: AlgoPassphrase,
+			numOfWords: 1, injectChars: true, trimUnsafeChars: PwNumbers}, ErrNoTrimSafeChars},
+		{"unknown_algorithm", Config{useLowerCase: true, pwAlgo: 99}, ErrUnknownAlgorithm},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			err := validateConfig(&testCase.config)
+			if testCase.expErr == nil && err != nil {
+				t.Errorf("Config validation failed: %v", err)
+			}
+			if testCase.expErr != nil && !errors.Is(err, testCase.expErr) {
+				t.Errorf("Config validation was expected to fail with %q, got: %v", testCase.expErr, err)
+			}
+		})
+	}
+}
+
+// Test parseAlgorithm() and getAlgorithmName()
+func TestParseAlgorithm(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		algoString string
+		expAlgo    int
+		shouldFail bool
+	}{
+		{"number_pronounceable", "0", AlgoPronounceable, false},
+		{"number_random", "1", AlgoRandom, false},
+		{"number_passphrase", "2", AlgoPassphrase, false},
+		{"name_pronounceable", "pronounceable", AlgoPronounceable, false},
+		{"name_random_mixed_case", "Random", AlgoRandom, false},
+		{"name_passphrase", "passphrase", AlgoPassphrase, false},
+		{"number_pattern", "3", AlgoPattern, false},
+		{"name_pattern", "pattern", AlgoPattern, false},
+		{"name_koremutake", "koremutake", AlgoKoremutake, false},
+		{"unknown_number", "99", 0, true},
+		{"unknown_name", "coinflip", 0, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			pwAlgo, err := parseAlgorithm(testCase.algoString)
+			if testCase.shouldFail {
+				if !errors.Is(err, ErrUnknownAlgorithm) {
+					t.Errorf("Algorithm parsing was expected to fail with ErrUnknownAlgorithm, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Algorithm parsing failed: %v", err)
+			}
+			if pwAlgo != testCase.expAlgo {
+				t.Errorf("Algorithm parsing returned wrong algorithm. Expected: %d, got: %d",
+					testCase.expAlgo, pwAlgo)
+			}
+			roundTrip, err := parseAlgorithm(getAlgorithmName(pwAlgo))
+			if err != nil || roundTrip != pwAlgo {
+				t.Errorf("Algorithm name %q does not round-trip: %d, %v", getAlgorithmName(pwAlgo),
+					roundTrip, err)
+			}
+		})
+	}
+}
+
 // Test Pwlength
 func TestGenLength(t *testing.T) {
 	testTable := []struct {
@@ -91,6 +402,51 @@ func TestGenLength(t *testing.T) {
 	}
 }
 
+// Test getPwLengthFromParams() with the different length distributions (chi-square test
+// against the expected distribution, critical value for 3 degrees of freedom at p = 0.000001)
+func TestLengthDistribution(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		lengthDist string
+		expShares  []float64
+	}{
+		{"uniform", LengthUniform, []float64{4, 4, 4, 4}},
+		{"default", "", []float64{4, 4, 4, 4}},
+		{"prefer_max", LengthPreferMax, []float64{1, 3, 5, 7}},
+		{"prefer_min", "PreferMin", []float64{7, 5, 3, 1}},
+	}
+
+	numOfSamples := 16000
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			config := Config{minPassLen: 1, maxPassLen: 4, lengthDist: testCase.lengthDist}
+			if err := validateLengthDistribution(config.lengthDist); err != nil {
+				t.Fatalf("Length distribution validation failed: %v", err)
+			}
+			lengthCount := make([]int, 5)
+			for i := 0; i < numOfSamples; i++ {
+				lengthCount[getPwLengthFromParams(&config)]++
+			}
+			var chiSquare float64
+			for i, expShare := range testCase.expShares {
+				expCount := float64(numOfSamples) * expShare / 16
+				deviation := float64(lengthCount[i+1]) - expCount
+				chiSquare += deviation * deviation / expCount
+			}
+			if chiSquare > 30.66 {
+				t.Errorf("Password lengths do not match the %q distribution (chi-square: %.2f, counts: %v)",
+					testCase.lengthDist, chiSquare, lengthCount[1:])
+			}
+		})
+	}
+
+	t.Run("unknown_distribution", func(t *testing.T) {
+		if err := validateLengthDistribution("bogus"); err == nil {
+			t.Errorf("Unknown length distribution was expected to fail")
+		}
+	})
+}
+
 // Test getRandChar
 func TestGetRandChar(t *testing.T) {
 	t.Run("return_value_is_A_B_or_C", func(t *testing.T) {
@@ -116,6 +472,63 @@ func TestGetRandChar(t *testing.T) {
 		}
 	})
 
+	t.Run("all_chars_of_long_range_are_used", func(t *testing.T) {
+		charBytes := make([]byte, 256)
+		for i := range charBytes {
+			charBytes[i] = byte(i)
+		}
+		charRange := string(charBytes)
+		randChar, err := getRandChar(&charRange, 256*200)
+		if err != nil {
+			t.Fatalf("Random character generation failed => %v", err.Error())
+		}
+		charCount := make(map[byte]int)
+		for i := 0; i < len(randChar); i++ {
+			charCount[randChar[i]]++
+		}
+		for _, curChar := range charBytes {
+			if charCount[curChar] == 0 {
+				t.Fatalf("Character %q of 256 character range was never selected", curChar)
+			}
+		}
+	})
+
+	// The critical values are the chi-square values with a p-value of 0.000001, so that a biased
+	// selection is detected without making the test flaky
+	t.Run("ascii_range_is_uniformly_distributed", func(t *testing.T) {
+		charRange := PwLowerChars + PwUpperChars + PwNumbers + PwSpecialChars
+		if chiSquare := getChiSquare(t, charRange, 1000); chiSquare > 172.9 {
+			t.Fatalf("Random character selection is not uniformly distributed. Chi-square: %.2f", chiSquare)
+		}
+	})
+
+	t.Run("multi_byte_range_is_uniformly_distributed", func(t *testing.T) {
+		charRange := "äöüßÄÖÜ€"
+		if chiSquare := getChiSquare(t, charRange, 5000); chiSquare > 41.8 {
+			t.Fatalf("Random character selection is not uniformly distributed. Chi-square: %.2f", chiSquare)
+		}
+	})
+
+	t.Run("multi_byte_range_returns_valid_utf8", func(t *testing.T) {
+		charRange := "äöüßÄÖÜ€"
+		randChar, err := getRandChar(&charRange, 1000)
+		if err != nil {
+			t.Fatalf("Random character generation failed => %v", err.Error())
+		}
+		if !utf8.ValidString(randChar) {
+			t.Fatalf("Random character generation returned invalid UTF-8 string: %q", randChar)
+		}
+		if utf8.RuneCountInString(randChar) != 1000 {
+			t.Fatalf("Generated random characters with 1000 chars returned wrong amount of chars: %v",
+				utf8.RuneCountInString(randChar))
+		}
+		for _, curChar := range randChar {
+			if !strings.ContainsRune(charRange, curChar) {
+				t.Fatalf("Random character generation returned invalid character: %q", curChar)
+			}
+		}
+	})
+
 	t.Run("fail", func(t *testing.T) {
 		charRange := "ABC"
 		randChar, err := getRandChar(&charRange, -2000)
@@ -126,6 +539,313 @@ func TestGetRandChar(t *testing.T) {
 	})
 }
 
+// Test getRandCharAllClasses() with all character classes enabled
+func TestGetRandCharAllClasses(t *testing.T) {
+	testConfig := Config{useLowerCase: true, useUpperCase: true, useNumber: true, useSpecial: true}
+	charRange := getCharRange(&testConfig)
+	charClasses := getCharClasses(&testConfig)
+
+	t.Run("length_4_contains_all_classes", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			pwString, err := getRandCharAllClasses(&charRange, 4, 0, 0, charClasses, edgeRanges{})
+			if err != nil {
+				t.Fatalf("Random password generation failed: %v", err)
+			}
+			for _, curClass := range charClasses {
+				if !strings.ContainsAny(pwString, curClass.charRange) {
+					t.Fatalf("Password %q does not contain any %s character", pwString, curClass.className)
+				}
+			}
+		}
+	})
+
+	t.Run("length_4_with_limits", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			pwString, err := getRandCharAllClasses(&charRange, 4, 1, 2, charClasses, edgeRanges{})
+			if err != nil {
+				t.Fatalf("Random password generation failed: %v", err)
+			}
+			if !hasAllCharClasses(pwString, charClasses) {
+				t.Fatalf("Password %q does not contain all character classes", pwString)
+			}
+		}
+	})
+
+	t.Run("length_shorter_than_classes", func(t *testing.T) {
+		if _, err := getRandCharAllClasses(&charRange, 3, 0, 0, charClasses, edgeRanges{}); err == nil {
+			t.Errorf("Random password generation was expected to fail for a length of 3")
+		}
+	})
+
+	t.Run("class_not_in_range", func(t *testing.T) {
+		numberRange := PwNumbers
+		if _, err := getRandCharAllClasses(&numberRange, 4, 0, 0, charClasses, edgeRanges{}); err == nil {
+			t.Errorf("Random password generation was expected to fail for unreachable classes")
+		}
+	})
+}
+
+// Test getRandCharBalanced() and calcBalancedEntropy() with different lengths
+func TestGetRandCharBalanced(t *testing.T) {
+	testConfig := Config{useLowerCase: true, useUpperCase: true, useNumber: true, useSpecial: true}
+	charClasses := getCharClasses(&testConfig)
+
+	testTable := []struct {
+		testName string
+		pwLength int
+		minCount int
+		maxCount int
+	}{
+		{"one_per_class", 4, 1, 1},
+		{"even_split", 12, 3, 3},
+		{"uneven_split", 14, 3, 4},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				pwString, err := getRandCharBalanced(testCase.pwLength, 0, 0, charClasses, edgeRanges{})
+				if err != nil {
+					t.Fatalf("Balanced password generation failed: %v", err)
+				}
+				if len(pwString) != testCase.pwLength {
+					t.Fatalf("Balanced password %q has the wrong length", pwString)
+				}
+				for _, curClass := range charClasses {
+					classCount := 0
+					for _, curChar := range pwString {
+						if strings.ContainsRune(curClass.charRange, curChar) {
+							classCount++
+						}
+					}
+					if classCount < testCase.minCount || classCount > testCase.maxCount {
+						t.Fatalf("Balanced password %q contains %d %s characters, expected %d to %d",
+							pwString, classCount, curClass.className, testCase.minCount, testCase.maxCount)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("with_limits", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			pwString, err := getRandCharBalanced(12, 1, 2, charClasses, edgeRanges{})
+			if err != nil {
+				t.Fatalf("Balanced password generation failed: %v", err)
+			}
+			if hasCharLimitViolation([]rune(pwString), 1, 2) {
+				t.Fatalf("Balanced password %q exceeds the repeat or sequence limit", pwString)
+			}
+		}
+	})
+
+	t.Run("length_shorter_than_classes", func(t *testing.T) {
+		if _, err := getRandCharBalanced(3, 0, 0, charClasses, edgeRanges{}); err == nil {
+			t.Errorf("Balanced password generation was expected to fail for a length of 3")
+		}
+	})
+
+	t.Run("edge_chars_impossible", func(t *testing.T) {
+		twoClasses := []charClass{{"lower case", PwLowerChars}, {"numeric", PwNumbers}}
+		pwEdges := edgeRanges{firstRange: PwNumbers, lastRange: PwNumbers}
+		_, err := getRandCharBalanced(2, 0, 0, twoClasses, pwEdges)
+		if err == nil || !strings.Contains(err.Error(), "starts and ends with the allowed characters") {
+			t.Errorf("Balanced password generation was expected to fail because of the edge characters, got: %v",
+				err)
+		}
+		if _, ok := calcBalancedEntropy(2, twoClasses, PwNumbers, PwNumbers); ok {
+			t.Errorf("Balanced entropy calculation was expected to report that no password can be generated")
+		}
+	})
+
+	t.Run("entropy", func(t *testing.T) {
+		singleClass := []charClass{{"numeric", PwNumbers}}
+		if entropy, _ := calcBalancedEntropy(8, singleClass, "", ""); math.Abs(entropy-calcEntropy(8, 10)) > 0.0001 {
+			t.Errorf("Balanced entropy of a single class is expected to be %.4f, got: %.4f",
+				calcEntropy(8, 10), entropy)
+		}
+		twoClasses := []charClass{{"lower case", PwLowerChars}, {"numeric", PwNumbers}}
+		if entropy, _ := calcBalancedEntropy(2, twoClasses, "", ""); math.Abs(entropy-math.Log2(520)) > 0.0001 {
+			t.Errorf("Balanced entropy of 2 classes with a length of 2 is expected to be %.4f, got: %.4f",
+				math.Log2(520), entropy)
+		}
+		if entropy, _ := calcBalancedEntropy(12, charClasses, "", ""); entropy >= calcEntropy(12, 94) {
+			t.Errorf("Balanced entropy is expected to be lower than the uniform entropy, got: %.4f", entropy)
+		}
+		entropy, _ := calcBalancedEntropy(2, twoClasses, PwNumbers, "")
+		if math.Abs(entropy-math.Log2(260)) > 0.0001 {
+			t.Errorf("Balanced entropy of 2 classes with a numeric first character is expected to be %.4f, "+
+				"got: %.4f", math.Log2(260), entropy)
+		}
+	})
+}
+
+// Test getRandCharLimited() with different repeat limits
+func TestGetRandCharLimitedRepeat(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		charRange  string
+		pwLength   int
+		maxRepeat  int
+		shouldFail bool
+	}{
+		{"no_repeats_two_chars", "ab", 20, 1, false},
+		{"no_repeats_numbers", "23456789", 20, 1, false},
+		{"two_repeats_two_chars", "ab", 20, 2, false},
+		{"single_char_within_limit", "a", 3, 3, false},
+		{"single_char_exceeds_limit", "a", 4, 3, true},
+		{"invalid_length", "ab", 0, 1, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				pwString, err := getRandCharLimited(&testCase.charRange, testCase.pwLength, testCase.maxRepeat, 0,
+					edgeRanges{})
+				if testCase.shouldFail {
+					if err == nil {
+						t.Fatalf("Random character generation succeeded but was expected to fail. Returned: %q",
+							pwString)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Random character generation failed: %v", err)
+				}
+				if len(pwString) != testCase.pwLength {
+					t.Fatalf("Generated password has wrong length. Expected: %d, got: %d",
+						testCase.pwLength, len(pwString))
+				}
+				repeatCount := 1
+				for j := 1; j < len(pwString); j++ {
+					if pwString[j] != pwString[j-1] {
+						repeatCount = 1
+						continue
+					}
+					repeatCount++
+					if repeatCount > testCase.maxRepeat {
+						t.Fatalf("Generated password %q has more than %d identical characters in a row",
+							pwString, testCase.maxRepeat)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Test getRandCharLimited() with different sequence limits
+func TestGetRandCharLimitedSequence(t *testing.T) {
+	testTable := []struct {
+		testName    string
+		charRange   string
+		maxRepeat   int
+		maxSequence int
+		shouldFail  bool
+	}{
+		{"no_sequences_numbers", PwNumbers, 0, 1, false},
+		{"max_2_sequence_numbers", PwNumbers, 0, 2, false},
+		{"max_2_sequence_letters", PwLowerChars + PwUpperChars, 0, 2, false},
+		{"no_sequences_and_repeats", "ace", 1, 1, false},
+		{"impossible_limits", "ab", 1, 1, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				pwString, err := getRandCharLimited(&testCase.charRange, 20, testCase.maxRepeat,
+					testCase.maxSequence, edgeRanges{})
+				if testCase.shouldFail {
+					if err == nil {
+						t.Fatalf("Random character generation succeeded but was expected to fail. Returned: %q",
+							pwString)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Random character generation failed: %v", err)
+				}
+				if hasCharLimitViolation([]rune(pwString), 0, testCase.maxSequence) {
+					t.Fatalf("Generated password %q has a sequence longer than %d characters", pwString,
+						testCase.maxSequence)
+				}
+			}
+		})
+	}
+}
+
+// Test hasCharLimitViolation() with passwords of different algorithms
+func TestHasCharLimitViolation(t *testing.T) {
+	testTable := []struct {
+		testName     string
+		pwString     string
+		maxRepeat    int
+		maxSequence  int
+		expViolation bool
+	}{
+		{"passphrase_with_repeat", "bookkeeper-lamp", 1, 0, true},
+		{"passphrase_within_limit", "bookkeeper-lamp", 2, 0, false},
+		{"pronounceable_with_repeat", "kattobe", 1, 0, true},
+		{"koremutake_without_repeat", "bamodrategi", 1, 0, false},
+		{"repeat_at_end", "xy!!!", 2, 0, true},
+		{"pattern_with_sequence", "abc-4711", 0, 2, true},
+		{"passphrase_with_sequence", "first-lamp", 0, 2, true},
+		{"passphrase_within_sequence_limit", "first-lamp", 0, 3, false},
+		{"ascending_letters", "xabcx", 0, 2, true},
+		{"descending_letters", "xCBAx", 0, 2, true},
+		{"ascending_numbers", "x456x", 0, 2, true},
+		{"descending_numbers", "x987x", 0, 2, true},
+		{"sequence_within_limit", "xabx", 0, 2, false},
+		{"sequence_of_four_within_limit", "abcd", 0, 4, false},
+		{"direction_change", "abab", 0, 1, true},
+		{"mixed_directions", "aba", 0, 2, false},
+		{"no_sequence", "q7Xm", 0, 1, false},
+		{"limits_disabled", "aaaa", 0, 0, false},
+		{"empty_password", "", 1, 0, false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if hasCharLimitViolation([]rune(testCase.pwString), testCase.maxRepeat,
+				testCase.maxSequence) != testCase.expViolation {
+				t.Errorf("hasCharLimitViolation(%q, %d, %d) was expected to return %v", testCase.pwString,
+					testCase.maxRepeat, testCase.maxSequence, testCase.expViolation)
+			}
+		})
+	}
+}
+
+// Test hasKeyboardWalk() with known keyboard walks and near-misses
+func TestHasKeyboardWalk(t *testing.T) {
+	testTable := []struct {
+		testName string
+		pwString string
+		maxWalk  int
+		expWalk  bool
+	}{
+		{"qwerty_row", "xqwerx", 3, true},
+		{"home_row", "asdf", 3, true},
+		{"upper_case_walk", "ASDF", 3, true},
+		{"backwards_walk", "rewq", 3, true},
+		{"diagonal_walk", "1qaz", 3, true},
+		{"diagonal_walk_other_direction", "zaq1", 3, true},
+		{"qwertz_walk", "tzui", 3, true},
+		{"azerty_walk", "azer", 3, true},
+		{"near_miss", "qwet", 3, false},
+		{"walk_within_limit", "qwer", 4, false},
+		{"no_walk", "q7Xm", 1, false},
+		{"disabled", "qwerty", 0, false},
+		{"empty_password", "", 1, false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if hasKeyboardWalk(testCase.pwString, testCase.maxWalk) != testCase.expWalk {
+				t.Errorf("hasKeyboardWalk(%q, %d) was expected to return %v", testCase.pwString,
+					testCase.maxWalk, testCase.expWalk)
+			}
+		})
+	}
+}
+
 // Test getCharRange() with different config settings
 func TestGetCharRange(t *testing.T) {
 	lowerCaseBytes := []int{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', 'q', 'r',
@@ -178,15 +898,1256 @@ func TestGetCharRange(t *testing.T) {
 	}
 }
 
-// Test Conversions
-func TestConvert(t *testing.T) {
+// Test getCharRange() with custom and excluded characters
+func TestGetCharRangeCustomChars(t *testing.T) {
 	testTable := []struct {
-		testName   string
-		givenVal   byte
-		expVal     string
-		shouldFail bool
+		testName     string
+		useNumber    bool
+		customChars  string
+		excludeChars string
+		expRange     string
 	}{
-		{"convert_A_to_Alfa", 'A', "Alfa", false},
+		{"custom_only", false, "@!?", "", "@!?"},
+		{"custom_and_numbers", true, "@!?", "", PwNumbers + "@!?"},
+		{"custom_duplicates_removed", true, "@@1!2?", "", PwNumbers + "@!?"},
+		{"custom_excluded", true, "@!?", "!3", "124567890@?"},
+		{"hyphen_excluded", false, "a-z", "-", "az"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				useNumber:    testCase.useNumber,
+				customChars:  testCase.customChars,
+				excludeChars: testCase.excludeChars,
+			}
+			charRange := getCharRange(&testConfig)
+			if charRange != testCase.expRange {
+				t.Errorf("Character range is not as expected. Expected: %q, got: %q",
+					testCase.expRange, charRange)
+			}
+		})
+	}
+}
+
+// Test getCharRange() with custom ambiguous characters (custom characters are never removed)
+func TestGetCharRangeAmbiguousChars(t *testing.T) {
+	testTable := []struct {
+		testName       string
+		ambiguousChars string
+		humanReadable  bool
+		expRange       string
+	}{
+		{"default_set", "", true, "23456789()"},
+		{"explicit_default_set", DefaultAmbiguousChars, true, "23456789()"},
+		{"custom_set", "1()", true, "234567890()"},
+		{"custom_set_human_readable_disabled", "1()", false, PwNumbers + "()"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				useNumber:      true,
+				customChars:    "()",
+				humanReadable:  testCase.humanReadable,
+				ambiguousChars: testCase.ambiguousChars,
+			}
+			charRange := getCharRange(&testConfig)
+			if charRange != testCase.expRange {
+				t.Errorf("Character range is not as expected. Expected: %q, got: %q",
+					testCase.expRange, charRange)
+			}
+		})
+	}
+}
+
+// Test getCharRange() with the selectable special character sets
+func TestGetCharRangeSpecialCharSet(t *testing.T) {
+	testTable := []struct {
+		testName       string
+		specialCharSet string
+		humanReadable  bool
+		expRange       string
+		shouldFail     bool
+	}{
+		{"empty_set_name", "", false, PwSpecialChars, false},
+		{"default_set", "default", false, PwSpecialChars, false},
+		{"url_safe_set", "urlsafe", false, PwSpecialCharsURLSafe, false},
+		{"url_safe_set_mixed_case", "URLSafe", false, PwSpecialCharsURLSafe, false},
+		{"url_safe_human_readable", "urlsafe", true, "-_~", false},
+		{"shell_safe_set", "shellsafe", false, PwSpecialCharsShellSafe, false},
+		{"unknown_set", "quotes", false, "", true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			err := validateSpecialCharSet(testCase.specialCharSet)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Errorf("Special character set validation succeeded but was expected to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Special character set validation failed: %v", err)
+			}
+			testConfig := Config{
+				useSpecial:     true,
+				specialCharSet: testCase.specialCharSet,
+				humanReadable:  testCase.humanReadable,
+			}
+			if charRange := getCharRange(&testConfig); charRange != testCase.expRange {
+				t.Errorf("Character range is not as expected. Expected: %q, got: %q", testCase.expRange,
+					charRange)
+			}
+			charClasses := getCharClasses(&testConfig)
+			if len(charClasses) != 1 || charClasses[0].charRange != testCase.expRange {
+				t.Errorf("Character classes are not as expected. Expected: %q, got: %v", testCase.expRange,
+					charClasses)
+			}
+		})
+	}
+}
+
+// Test getCharRange() and validateCharRange() with layout-safe characters
+func TestGetCharRangeLayoutSafe(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		config     Config
+		expRange   string
+		shouldFail bool
+	}{
+		{"lower_case", Config{useLowerCase: true}, "bcdefghijklnoprstuvx", false},
+		{"upper_case", Config{useUpperCase: true}, "BCDEFGHIJKLNOPRSTUVX", false},
+		{"human_readable", Config{useLowerCase: true, humanReadable: true}, "bcdefghjknprstuvx", false},
+		{"custom_chars_kept", Config{useLowerCase: true, customChars: "z"}, "bcdefghijklnoprstuvxz", false},
+		{"numbers_fail", Config{useLowerCase: true, useNumber: true}, "bcdefghijklnoprstuvx", true},
+		{"specials_fail", Config{useLowerCase: true, useSpecial: true}, "bcdefghijklnoprstuvx", true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testCase.config.layoutSafe = true
+			if charRange := getCharRange(&testCase.config); charRange != testCase.expRange {
+				t.Errorf("Character range is not as expected. Expected: %q, got: %q", testCase.expRange,
+					charRange)
+			}
+			err := validateCharRange(&testCase.config)
+			if testCase.shouldFail && err == nil {
+				t.Errorf("Character range validation succeeded but was expected to fail")
+			}
+			if !testCase.shouldFail && err != nil {
+				t.Errorf("Character range validation failed: %v", err)
+			}
+		})
+	}
+}
+
+// Test validateCharRange() with different exclusion settings
+func TestValidateCharRange(t *testing.T) {
+	testTable := []struct {
+		testName      string
+		excludeChars  string
+		useNumber     bool
+		useSpecial    bool
+		humanReadable bool
+		shouldFail    bool
+	}{
+		{"nothing_excluded", "", true, true, false, false},
+		{"some_numbers_excluded", "123", true, false, false, false},
+		{"all_numbers_excluded", PwNumbers, true, false, false, true},
+		{"all_human_numbers_excluded", "23456789", true, false, true, true},
+		{"all_numbers_excluded_numbers_disabled", PwNumbers, false, false, false, false},
+		{"all_specials_excluded", PwSpecialChars, false, true, false, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				useLowerCase:  true,
+				useUpperCase:  true,
+				useNumber:     testCase.useNumber,
+				useSpecial:    testCase.useSpecial,
+				humanReadable: testCase.humanReadable,
+				excludeChars:  testCase.excludeChars,
+			}
+			err := validateCharRange(&testConfig)
+			if testCase.shouldFail && err == nil {
+				t.Errorf("Character range validation succeeded but was expected to fail. Excluded: %q",
+					testCase.excludeChars)
+			}
+			if !testCase.shouldFail && err != nil {
+				t.Errorf("Character range validation failed: %v", err)
+			}
+		})
+	}
+
+	t.Run("all_numbers_ambiguous", func(t *testing.T) {
+		testConfig := Config{useNumber: true, humanReadable: true, ambiguousChars: PwNumbers}
+		if err := validateCharRange(&testConfig); err == nil {
+			t.Errorf("Character range validation succeeded but was expected to fail")
+		}
+	})
+}
+
+// Test getPronounceableSyllables() with different config settings
+func TestGetPronounceableSyllables(t *testing.T) {
+	testTable := []struct {
+		testName      string
+		allowedChars  string
+		useLowerCase  bool
+		useUpperCase  bool
+		useNumber     bool
+		useSpecial    bool
+		humanReadable bool
+		shouldFail    bool
+	}{
+		{"lowercase_only", PronConsonants + PronVowels, true, false, false, false, false, false},
+		{"uppercase_only", "BCDFGHJKLMNPRSTVWXZAEIOUY", false, true, false, false, false, false},
+		{"lowercase_human", "bcdfghjkmnprstvwxzaeuy", true, false, false, false, true, false},
+		{"lowercase_number", PronConsonants + PronVowels + PwNumbers, true, false, true, false, false, false},
+		{"lowercase_special", PronConsonants + PronVowels + PwSpecialChars, true, false, false, true, false, false},
+		{"number_only_fails", "", false, false, true, false, false, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			config.useLowerCase = testCase.useLowerCase
+			config.useUpperCase = testCase.useUpperCase
+			config.useNumber = testCase.useNumber
+			config.useSpecial = testCase.useSpecial
+			config.humanReadable = testCase.humanReadable
+			config.excludeChars = ""
+			for i := 0; i < 100; i++ {
+				pwSyllables, err := getPronounceableSyllables(&config, 20)
+				if testCase.shouldFail {
+					if err == nil {
+						t.Fatalf("Pronounceable password generation succeeded but was expected to fail. "+
+							"Returned: %q", pwSyllables)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Pronounceable password generation failed: %v", err.Error())
+				}
+				pwString := strings.Join(pwSyllables, "")
+				if len(pwString) != 20 {
+					t.Fatalf("Pronounceable password has wrong length. Expected: 20, got: %v", len(pwString))
+				}
+				for _, curChar := range pwString {
+					if !strings.ContainsRune(testCase.allowedChars, curChar) {
+						t.Fatalf("Pronounceable password contains invalid character: %q", curChar)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("syllables_reproduce_password", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, useNumber: true}
+		for i := 0; i < 100; i++ {
+			pwSyllables, err := getPronounceableSyllables(&testConfig, 21)
+			if err != nil {
+				t.Fatalf("Pronounceable syllable generation failed: %v", err.Error())
+			}
+			if pwString := strings.Join(pwSyllables, ""); len(pwString) != 21 {
+				t.Fatalf("Joined syllables have wrong length. Expected: 21, got: %v (%q)", len(pwString),
+					pwSyllables)
+			}
+			for _, curSyllable := range pwSyllables {
+				if curSyllable == "" || len(curSyllable) > 3 {
+					t.Fatalf("Pronounceable password contains invalid syllable: %q", curSyllable)
+				}
+				if strings.ContainsAny(curSyllable, PwNumbers) && len(curSyllable) != 1 {
+					t.Fatalf("Number was not returned as its own element: %q", curSyllable)
+				}
+			}
+		}
+	})
+
+	t.Run("custom_syllable_templates", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, syllableTemplates: parseSyllableTemplates(" cv, ,CCV")}
+		if len(testConfig.syllableTemplates) != 2 {
+			t.Fatalf("Expected 2 parsed syllable templates, got: %q", testConfig.syllableTemplates)
+		}
+		for i := 0; i < 100; i++ {
+			pwSyllables, err := getPronounceableSyllables(&testConfig, 24)
+			if err != nil {
+				t.Fatalf("Pronounceable syllable generation failed: %v", err.Error())
+			}
+			for _, curSyllable := range pwSyllables[:len(pwSyllables)-1] {
+				curTemplate := ""
+				for _, curChar := range curSyllable {
+					if strings.ContainsRune(PronVowels, curChar) {
+						curTemplate = curTemplate + "V"
+						continue
+					}
+					curTemplate = curTemplate + "C"
+				}
+				if curTemplate != "CV" && curTemplate != "CCV" {
+					t.Fatalf("Syllable %q does not match the templates CV or CCV", curSyllable)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid_syllable_templates", func(t *testing.T) {
+		for _, curTemplates := range [][]string{{"CX"}, {"CV", ""}, {"CCCCCCCCV"}} {
+			testConfig := Config{useLowerCase: true, syllableTemplates: curTemplates}
+			if _, err := getPronounceableSyllables(&testConfig, 12); err == nil {
+				t.Errorf("Pronounceable password generation with templates %q was expected to fail",
+					curTemplates)
+			}
+		}
+	})
+
+	t.Run("no_vowels_left", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, excludeChars: PronVowels}
+		if _, err := getPronounceableSyllables(&testConfig, 12); !errors.Is(err, ErrNoSyllableChars) {
+			t.Errorf("Pronounceable password generation without vowels was expected to fail with %q, got: %v",
+				ErrNoSyllableChars, err)
+		}
+	})
+
+	t.Run("vowel_templates_without_consonants", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, excludeChars: PronConsonants, syllableTemplates: []string{"V"}}
+		pwSyllables, err := getPronounceableSyllables(&testConfig, 12)
+		if err != nil {
+			t.Fatalf("Pronounceable syllable generation failed: %v", err.Error())
+		}
+		for _, curChar := range strings.Join(pwSyllables, "") {
+			if !strings.ContainsRune(PronVowels, curChar) {
+				t.Fatalf("Pronounceable password contains a character that is not a vowel: %q", curChar)
+			}
+		}
+	})
+
+	t.Run("no_trim_unsafe_last_char", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, customChars: " ", trimUnsafeChars: DefaultTrimUnsafeChars}
+		for _, pwLength := range []int{1, 2, 3, 4, 5} {
+			for i := 0; i < 100; i++ {
+				pwSyllables, err := getPronounceableSyllables(&testConfig, pwLength)
+				if err != nil {
+					t.Fatalf("Pronounceable syllable generation failed: %v", err.Error())
+				}
+				if pwString := strings.Join(pwSyllables, ""); !hasTrimSafeEdges(pwString,
+					testConfig.trimUnsafeChars) {
+					t.Fatalf("Pronounceable password %q starts or ends with whitespace", pwString)
+				}
+			}
+		}
+	})
+
+	t.Run("fail_on_invalid_length", func(t *testing.T) {
+		config.useLowerCase = true
+		pwSyllables, err := getPronounceableSyllables(&config, 0)
+		if err == nil {
+			t.Fatalf("Pronounceable password generation expected to fail, but returned a value => %q",
+				pwSyllables)
+		}
+	})
+}
+
+// Test getPassphrase() with different config settings
+func TestGetPassphrase(t *testing.T) {
+	wordList := []string{"alpha", "bravo", "charlie", "delta"}
+	testTable := []struct {
+		testName      string
+		useLowerCase  bool
+		useUpperCase  bool
+		numOfWords    int
+		wordSeparator string
+		expWords      []string
+		shouldFail    bool
+	}{
+		{"lowercase_words", true, false, 4, "-", wordList, false},
+		{"capitalized_words", true, true, 4, "-", []string{"Alpha", "Bravo", "Charlie", "Delta"}, false},
+		{"uppercase_words", false, true, 4, "-", []string{"ALPHA", "BRAVO", "CHARLIE", "DELTA"}, false},
+		{"custom_separator", true, false, 8, "_", wordList, false},
+		{"single_word", true, false, 1, "-", wordList, false},
+		{"zero_words", true, false, 0, "-", wordList, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				useLowerCase:  testCase.useLowerCase,
+				useUpperCase:  testCase.useUpperCase,
+				numOfWords:    testCase.numOfWords,
+				wordSeparator: testCase.wordSeparator,
+			}
+			passPhrase, err := getPassphrase(&testConfig, wordList)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Fatalf("Passphrase generation succeeded but was expected to fail. Returned: %v", passPhrase)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Passphrase generation failed: %v", err)
+			}
+			phraseWords := strings.Split(passPhrase, testCase.wordSeparator)
+			if len(phraseWords) != testCase.numOfWords {
+				t.Fatalf("Passphrase has wrong amount of words. Expected: %v, got: %v",
+					testCase.numOfWords, len(phraseWords))
+			}
+			for _, curWord := range phraseWords {
+				if !containsString(testCase.expWords, curWord) {
+					t.Errorf("Passphrase contains unexpected word: %q", curWord)
+				}
+			}
+		})
+	}
+
+	t.Run("injected_chars_at_word_boundaries", func(t *testing.T) {
+		for _, numOfWords := range []int{1, 4} {
+			testConfig := Config{useLowerCase: true, useNumber: true, useSpecial: true, injectChars: true,
+				numOfWords: numOfWords, wordSeparator: " ", excludeChars: " "}
+			for i := 0; i < 100; i++ {
+				passPhrase, err := getPassphrase(&testConfig, wordList)
+				if err != nil {
+					t.Fatalf("Passphrase generation failed: %v", err)
+				}
+				numOfDigits, numOfSpecials := 0, 0
+				for _, curWord := range strings.Split(passPhrase, " ") {
+					plainWord := strings.Trim(curWord, PwNumbers+PwSpecialChars)
+					if !containsString(wordList, plainWord) {
+						t.Fatalf("Passphrase %q contains a character inside of word %q", passPhrase, curWord)
+					}
+					for _, curChar := range curWord {
+						if strings.ContainsRune(PwNumbers, curChar) {
+							numOfDigits++
+						}
+						if strings.ContainsRune(PwSpecialChars, curChar) {
+							numOfSpecials++
+						}
+					}
+				}
+				if numOfDigits != 1 || numOfSpecials != 1 {
+					t.Fatalf("Passphrase %q was expected to contain exactly one number and one special "+
+						"character", passPhrase)
+				}
+			}
+		}
+	})
+
+	t.Run("trim_unsafe_chars_only_between_words", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, useNumber: true, injectChars: true, numOfWords: 2,
+			wordSeparator: "-", trimUnsafeChars: "0123456789"}
+		for i := 0; i < 100; i++ {
+			passPhrase, err := getPassphrase(&testConfig, wordList)
+			if err != nil {
+				t.Fatalf("Passphrase generation failed: %v", err)
+			}
+			if !hasTrimSafeEdges(passPhrase, testConfig.trimUnsafeChars) {
+				t.Fatalf("Passphrase %q starts or ends with an injected number", passPhrase)
+			}
+		}
+		testConfig.numOfWords = 1
+		if _, err := getPassphrase(&testConfig, wordList); !errors.Is(err, ErrNoTrimSafeChars) {
+			t.Errorf("Passphrase generation was expected to fail with %q, got: %v", ErrNoTrimSafeChars, err)
+		}
+	})
+
+	t.Run("no_inject_without_classes", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, injectChars: true, numOfWords: 4, wordSeparator: "-"}
+		passPhrase, err := getPassphrase(&testConfig, wordList)
+		if err != nil {
+			t.Fatalf("Passphrase generation failed: %v", err)
+		}
+		if strings.ContainsAny(passPhrase, PwNumbers) {
+			t.Errorf("Passphrase %q contains an injected number, but numbers are disabled", passPhrase)
+		}
+	})
+
+	t.Run("fail_on_empty_word_list", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, numOfWords: 4, wordSeparator: "-"}
+		passPhrase, err := getPassphrase(&testConfig, nil)
+		if err == nil {
+			t.Fatalf("Passphrase generation expected to fail, but returned a value => %v", passPhrase)
+		}
+	})
+}
+
+// Test readWordList()
+func TestReadWordList(t *testing.T) {
+	var testWords []string
+	for i := 0; i < MinWordListSize; i++ {
+		testWords = append(testWords, fmt.Sprintf("word%04d", i))
+	}
+
+	t.Run("read_words_from_file", func(t *testing.T) {
+		fileContent := "# comment line\n  Word0000 \n\nword0000\n" + strings.Join(testWords, "\n")
+		wordList, err := readWordList(writeTestFile(t, fileContent))
+		if err != nil {
+			t.Fatalf("Reading word list failed: %v", err)
+		}
+		if strings.Join(wordList, ",") != strings.Join(testWords, ",") {
+			t.Fatalf("Word list is not as expected. Expected %v words, got: %v", len(testWords),
+				len(wordList))
+		}
+	})
+
+	t.Run("fail_on_too_small_file", func(t *testing.T) {
+		fileContent := strings.Join(testWords[1:], "\n") + "\n" + testWords[1] + "\n# " + testWords[0]
+		_, err := readWordList(writeTestFile(t, fileContent))
+		if !errors.Is(err, ErrWordListTooSmall) {
+			t.Fatalf("Reading too small word list was expected to fail with ErrWordListTooSmall, got: %v", err)
+		}
+	})
+
+	t.Run("fail_on_empty_file", func(t *testing.T) {
+		if _, err := readWordList(writeTestFile(t, "\n\n")); err == nil {
+			t.Fatalf("Reading empty word list succeeded but was expected to fail")
+		}
+	})
+
+	t.Run("fail_on_missing_file", func(t *testing.T) {
+		if _, err := readWordList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+			t.Fatalf("Reading missing word list succeeded but was expected to fail")
+		}
+	})
+}
+
+// Test parsePattern() and getPatternPassword() with different patterns
+func TestPatternPassword(t *testing.T) {
+	testTable := []struct {
+		testName      string
+		pwPattern     string
+		humanReadable bool
+		excludeChars  string
+		expRanges     []string
+		shouldFail    bool
+	}{
+		{"all_tokens", "?l?u?d?s", false, "", []string{PwLowerChars, PwUpperChars, PwNumbers, PwSpecialChars},
+			false},
+		{"human_readable_tokens", "?l?d", true, "", []string{"abcdefghjkmnpqrstuvwxyz", "23456789"}, false},
+		{"any_token", "?a", false, "", []string{PwLowerChars + PwUpperChars + PwNumbers + PwSpecialChars},
+			false},
+		{"literals", "ab-?d??", false, "", []string{"a", "b", "-", PwNumbers, "?"}, false},
+		{"excluded_chars", "?d", false, "13579", []string{"24680"}, false},
+		{"literals_not_excluded", "a?d", false, "a", []string{"a", PwNumbers}, false},
+		{"empty_pattern", "", false, "", nil, true},
+		{"unknown_token", "?l?x", false, "", nil, true},
+		{"incomplete_token", "?l?", false, "", nil, true},
+		{"all_chars_excluded", "?d", false, PwNumbers, nil, true},
+		{"inner_whitespace", "?d ?d", false, "", []string{PwNumbers, " ", PwNumbers}, false},
+		{"leading_whitespace", " ?d", false, "", nil, true},
+		{"trailing_whitespace", "?d\u00a0", false, "", nil, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				pwPattern:       testCase.pwPattern,
+				humanReadable:   testCase.humanReadable,
+				excludeChars:    testCase.excludeChars,
+				trimUnsafeChars: DefaultTrimUnsafeChars,
+			}
+			charRanges, err := parsePattern(&testConfig)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Fatalf("Pattern parsing succeeded but was expected to fail. Returned: %q", charRanges)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Pattern parsing failed: %v", err)
+			}
+			if strings.Join(charRanges, "|") != strings.Join(testCase.expRanges, "|") {
+				t.Fatalf("Pattern parsing returned wrong character ranges. Expected: %q, got: %q",
+					testCase.expRanges, charRanges)
+			}
+			pwString, err := getPatternPassword(charRanges)
+			if err != nil {
+				t.Fatalf("Pattern password generation failed: %v", err)
+			}
+			for i, curChar := range []rune(pwString) {
+				if !strings.ContainsRune(charRanges[i], curChar) {
+					t.Errorf("Pattern password character %q at position %d does not match pattern", curChar, i)
+				}
+			}
+		})
+	}
+
+	t.Run("trim_unsafe_edge_tokens", func(t *testing.T) {
+		for _, curPattern := range []string{"?d", "?d?d", "?d?d?d"} {
+			testConfig := Config{pwPattern: curPattern, trimUnsafeChars: "0"}
+			charRanges, err := parsePattern(&testConfig)
+			if err != nil {
+				t.Fatalf("Pattern parsing failed: %v", err)
+			}
+			if charRanges[0] != "123456789" || charRanges[len(charRanges)-1] != "123456789" {
+				t.Errorf("Edge tokens of pattern %q still contain the trim-unsafe character: %q", curPattern,
+					charRanges)
+			}
+			if len(charRanges) == 3 && charRanges[1] != PwNumbers {
+				t.Errorf("Inner token of pattern %q was expected to be unchanged: %q", curPattern, charRanges[1])
+			}
+		}
+	})
+}
+
+// Test koremutake password generation, encoding and decoding
+func TestKoremutake(t *testing.T) {
+	testTable := []struct {
+		testName  string
+		num       int64
+		expString string
+	}{
+		{"encode_0", 0, "ba"},
+		{"encode_39", 39, "ko"},
+		{"encode_67", 67, "re"},
+		{"encode_127", 127, "tre"},
+		{"encode_128", 128, "beba"},
+		{"encode_koremutake", 10610353957, "koremutake"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			koremutakeString := koremutakeEncode(big.NewInt(testCase.num))
+			if koremutakeString != testCase.expString {
+				t.Errorf("Koremutake encoding of %d failed. Expected: %q, got: %q", testCase.num,
+					testCase.expString, koremutakeString)
+			}
+			num, err := koremutakeDecode(koremutakeString)
+			if err != nil {
+				t.Fatalf("Koremutake decoding failed: %v", err)
+			}
+			if num.Int64() != testCase.num {
+				t.Errorf("Koremutake decoding of %q failed. Expected: %d, got: %d", koremutakeString,
+					testCase.num, num.Int64())
+			}
+		})
+	}
+
+	t.Run("decode_invalid_syllable", func(t *testing.T) {
+		if _, err := koremutakeDecode("koxyre"); err == nil {
+			t.Errorf("Koremutake decoding of invalid syllable was expected to fail")
+		}
+	})
+
+	t.Run("decode_trailing_consonant", func(t *testing.T) {
+		if _, err := koremutakeDecode("kor"); err == nil {
+			t.Errorf("Koremutake decoding of trailing consonant was expected to fail")
+		}
+	})
+
+	t.Run("generate_password_with_syllables", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			pwString, err := getKoremutakePassword(4)
+			if err != nil {
+				t.Fatalf("Koremutake password generation failed: %v", err)
+			}
+			if len(koremutakeSplit(pwString)) != 4 {
+				t.Fatalf("Koremutake password %q does not consist of 4 syllables", pwString)
+			}
+			if _, err := koremutakeDecode(pwString); err != nil {
+				t.Fatalf("Koremutake password %q cannot be decoded: %v", pwString, err)
+			}
+		}
+	})
+
+	t.Run("generate_password_fails_on_invalid_length", func(t *testing.T) {
+		if pwString, err := getKoremutakePassword(0); err == nil {
+			t.Errorf("Koremutake password generation was expected to fail, but returned: %q", pwString)
+		}
+	})
+}
+
+// Test SHA512-crypt hashing with the test vectors of the specification
+func TestSha512Crypt(t *testing.T) {
+	testTable := []struct {
+		testName    string
+		pwString    string
+		salt        string
+		numOfRounds int
+		expHash     string
+	}{
+		{"default_rounds", "Hello world!", "saltstring", CryptDefaultRounds,
+			"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"10000_rounds_long_salt", "Hello world!", "saltstringsaltstring", 10000,
+			"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"long_password", "we have a short salt string but not a short password", "short", 77777,
+			"$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			cryptHash, err := sha512Crypt(testCase.pwString, testCase.salt, testCase.numOfRounds)
+			if err != nil {
+				t.Fatalf("SHA512-crypt hashing failed: %v", err)
+			}
+			if cryptHash != testCase.expHash {
+				t.Errorf("SHA512-crypt hash is not as expected. Expected: %q, got: %q", testCase.expHash,
+					cryptHash)
+			}
+		})
+	}
+
+	t.Run("fail_on_too_few_rounds", func(t *testing.T) {
+		if cryptHash, err := sha512Crypt("Hello world!", "saltstring", 10); err == nil {
+			t.Errorf("SHA512-crypt hashing was expected to fail, but returned: %q", cryptHash)
+		}
+	})
+
+	t.Run("random_salt", func(t *testing.T) {
+		cryptHash, err := getCryptHash("Hello world!")
+		if err != nil {
+			t.Fatalf("SHA512-crypt hashing failed: %v", err)
+		}
+		hashParts := strings.Split(cryptHash, "$")
+		if len(hashParts) != 4 || hashParts[1] != "6" || len(hashParts[2]) != CryptSaltLength {
+			t.Fatalf("SHA512-crypt hash has an unexpected format: %q", cryptHash)
+		}
+		expHash, _ := sha512Crypt("Hello world!", hashParts[2], CryptDefaultRounds)
+		if cryptHash != expHash {
+			t.Errorf("SHA512-crypt hash with random salt does not verify. Expected: %q, got: %q", expHash,
+				cryptHash)
+		}
+	})
+}
+
+// Test getEntropy() with different config settings
+func TestGetEntropy(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		pwAlgo     int
+		minPassLen int
+		numOfWords int
+		charRange  string
+		wordList   []string
+		expEntropy float64
+		shouldFail bool
+	}{
+		{"random_16_chars_of_16", AlgoRandom, 16, 0, "0123456789abcdef", nil, 64, false},
+		{"random_10_chars_of_2", AlgoRandom, 10, 0, "ab", nil, 10, false},
+		{"random_zero_length", AlgoRandom, 0, 0, "ab", nil, 1, false},
+		{"random_empty_range", AlgoRandom, 16, 0, "", nil, 0, true},
+		{"passphrase_4_words_of_4", AlgoPassphrase, 0, 4, "", []string{"a", "b", "c", "d"}, 8, false},
+		{"passphrase_empty_list", AlgoPassphrase, 0, 4, "", nil, 0, true},
+		{"pronounceable_unsupported", AlgoPronounceable, 16, 0, "ab", nil, 0, true},
+		{"pattern_4_digits", AlgoPattern, 0, 0, "", nil, 4 * math.Log2(10), false},
+		{"koremutake_3_syllables", AlgoKoremutake, 3, 0, "", nil, 21, false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				pwAlgo:     testCase.pwAlgo,
+				minPassLen: testCase.minPassLen,
+				numOfWords: testCase.numOfWords,
+				pwPattern:  "?d-?d?d?d",
+			}
+			entropy, err := getEntropy(&testConfig, testCase.charRange, testCase.wordList)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Errorf("Entropy calculation succeeded but was expected to fail. Returned: %v", entropy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Entropy calculation failed: %v", err)
+			}
+			if entropy != testCase.expEntropy {
+				t.Errorf("Entropy calculation returned wrong value. Expected: %v, got: %v",
+					testCase.expEntropy, entropy)
+			}
+		})
+	}
+
+	t.Run("passphrase_with_injected_number", func(t *testing.T) {
+		testConfig := Config{pwAlgo: AlgoPassphrase, numOfWords: 4, useNumber: true, injectChars: true}
+		entropy, err := getEntropy(&testConfig, "", []string{"a", "b", "c", "d"})
+		if err != nil {
+			t.Fatalf("Entropy calculation failed: %v", err)
+		}
+		expEntropy := 8 + math.Log2(10) + math.Log2(5)
+		if math.Abs(entropy-expEntropy) > 0.001 {
+			t.Errorf("Entropy calculation returned wrong value. Expected: %.2f, got: %.2f", expEntropy, entropy)
+		}
+	})
+
+	t.Run("passphrase_with_trim_unsafe_injected_number", func(t *testing.T) {
+		testConfig := Config{pwAlgo: AlgoPassphrase, numOfWords: 4, useNumber: true, injectChars: true,
+			trimUnsafeChars: "0"}
+		entropy, err := getEntropy(&testConfig, "", []string{"a", "b", "c", "d"})
+		if err != nil {
+			t.Fatalf("Entropy calculation failed: %v", err)
+		}
+		expEntropy := 8 + math.Log2(3*10+2*9)
+		if math.Abs(entropy-expEntropy) > 0.001 {
+			t.Errorf("Entropy calculation returned wrong value. Expected: %.2f, got: %.2f", expEntropy, entropy)
+		}
+	})
+
+	edgeTable := []struct {
+		testName   string
+		config     Config
+		expEntropy float64
+	}{
+		{"random_with_first_char", Config{useLowerCase: true, useUpperCase: true, useNumber: true,
+			firstCharModes: "N", minPassLen: 8}, math.Log2(10) + 7*math.Log2(62)},
+		{"random_with_last_char", Config{useLowerCase: true, useNumber: true, lastCharModes: "l",
+			minPassLen: 3}, 2*math.Log2(36) + math.Log2(26)},
+		{"random_single_char_with_edges", Config{useLowerCase: true, useNumber: true, firstCharModes: "LN",
+			lastCharModes: "N", minPassLen: 1}, math.Log2(10)},
+		{"random_all_classes", Config{useLowerCase: true, useNumber: true, requireAllModes: true,
+			minPassLen: 2}, math.Log2(520)},
+		{"random_all_classes_with_last_char", Config{useLowerCase: true, useNumber: true, requireAllModes: true,
+			lastCharModes: "N", minPassLen: 2}, math.Log2(260)},
+		{"random_single_char_with_whitespace", Config{customChars: "ab ", trimUnsafeChars: DefaultTrimUnsafeChars,
+			minPassLen: 1}, 1},
+		{"random_two_chars_with_whitespace", Config{customChars: "ab ", trimUnsafeChars: DefaultTrimUnsafeChars,
+			minPassLen: 2}, 2},
+		{"random_three_chars_with_whitespace", Config{customChars: "ab ", trimUnsafeChars: DefaultTrimUnsafeChars,
+			minPassLen: 3}, 2 + math.Log2(3)},
+	}
+	for _, testCase := range edgeTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testCase.config.pwAlgo = AlgoRandom
+			entropy, err := getEntropy(&testCase.config, getCharRange(&testCase.config), nil)
+			if err != nil {
+				t.Fatalf("Entropy calculation failed: %v", err)
+			}
+			if math.Abs(entropy-testCase.expEntropy) > 0.0001 {
+				t.Errorf("Entropy calculation returned wrong value. Expected: %.4f, got: %.4f",
+					testCase.expEntropy, entropy)
+			}
+		})
+	}
+
+	t.Run("passphrase_with_random_separators", func(t *testing.T) {
+		testConfig := Config{pwAlgo: AlgoPassphrase, numOfWords: 4, separatorSet: "-._-"}
+		entropy, err := getEntropy(&testConfig, "", []string{"a", "b", "c", "d"})
+		if err != nil {
+			t.Fatalf("Entropy calculation failed: %v", err)
+		}
+		expEntropy := 8 + 3*math.Log2(3)
+		if math.Abs(entropy-expEntropy) > 0.001 {
+			t.Errorf("Entropy calculation returned wrong value. Expected: %.2f, got: %.2f", expEntropy, entropy)
+		}
+	})
+}
+
+// Test checkHibp() against a local HIBP API server
+func TestCheckHibp(t *testing.T) {
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	hibpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/range/5baa6":
+			_, _ = fmt.Fprint(w, "003D68EB55068C33ACE09247EE4C639306B:3\r\n"+
+				"1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n")
+		case strings.HasPrefix(r.URL.Path, "/unavailable/"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = fmt.Fprint(w, "003D68EB55068C33ACE09247EE4C639306B:3\r\n")
+		}
+	}))
+	defer hibpServer.Close()
+	defer func(origURL string) { hibpAPIURL = origURL }(hibpAPIURL)
+	hibpAPIURL = hibpServer.URL + "/range/"
+
+	t.Run("pwned_password", func(t *testing.T) {
+		isPwned, err := checkHibp("password")
+		if err != nil {
+			t.Fatalf("HIBP check failed: %v", err)
+		}
+		if !isPwned {
+			t.Errorf("HIBP check was expected to report password as pwned")
+		}
+	})
+
+	t.Run("not_pwned_password", func(t *testing.T) {
+		isPwned, err := checkHibp("ZsmxJ4tKbtNbD4WTekaR")
+		if err != nil {
+			t.Fatalf("HIBP check failed: %v", err)
+		}
+		if isPwned {
+			t.Errorf("HIBP check was expected to not report password as pwned")
+		}
+	})
+
+	t.Run("api_error", func(t *testing.T) {
+		hibpAPIURL = hibpServer.URL + "/unavailable/"
+		defer func() { hibpAPIURL = hibpServer.URL + "/range/" }()
+		if _, err := checkHibp("password"); err == nil {
+			t.Errorf("HIBP check was expected to fail on HTTP error status")
+		}
+	})
+}
+
+// Test getCrackTime() and formatCrackTime() with hand-computed values
+func TestCrackTime(t *testing.T) {
+	if crackTime := getCrackTime(10, 1); crackTime != 512 {
+		t.Errorf("Crack time of 10 bits at 1 guess per second is expected to be 512s, got: %v", crackTime)
+	}
+	if crackTime := getCrackTime(64, 1e10); math.Abs(crackTime-922337203.6854776) > 0.001 {
+		t.Errorf("Crack time of 64 bits at 10 billion guesses per second is expected to be 922337203.69s, "+
+			"got: %v", crackTime)
+	}
+
+	year := 365.25 * 24 * 3600
+	testTable := []struct {
+		testName  string
+		crackTime float64
+		expString string
+	}{
+		{"less_than_a_second", 0.5, "less than a second"},
+		{"one_second", 1, "about 1 second"},
+		{"seconds", 42, "about 42 seconds"},
+		{"minutes_rounded", 90, "about 2 minutes"},
+		{"one_hour", 3600, "about 1 hour"},
+		{"days", 3 * 24 * 3600, "about 3 days"},
+		{"years", 3 * year, "about 3 years"},
+		{"below_max", 999999 * year, "about 999999 years"},
+		{"max", MaxCrackTimeYears * year, "more than a million years"},
+		{"infinite", math.Inf(1), "more than a million years"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if crackString := formatCrackTime(testCase.crackTime); crackString != testCase.expString {
+				t.Errorf("Crack time is not formatted as expected. Expected: %q, got: %q", testCase.expString,
+					crackString)
+			}
+		})
+	}
+}
+
+// Test getEntropy() with repeat and sequence limits
+func TestGetEntropyLimits(t *testing.T) {
+	testTable := []struct {
+		testName    string
+		pwAlgo      int
+		minPassLen  int
+		charRange   string
+		maxRepeat   int
+		maxSequence int
+		expEntropy  float64
+	}{
+		{"random_no_repeats_of_2", AlgoRandom, 10, "ab", 1, 0, 1},
+		{"random_no_sequences", AlgoRandom, 16, "0123456789abcdef", 0, 1, 4 + 15*math.Log2(14)},
+		{"random_long_sequences", AlgoRandom, 16, "0123456789abcdef", 0, 3, 4 + 15*math.Log2(15)},
+		{"random_impossible", AlgoRandom, 2, "a", 1, 0, 0},
+		{"pattern_no_repeats", AlgoPattern, 0, "", 1, 0, 2*math.Log2(10) + 2*math.Log2(9)},
+		{"pattern_no_sequences", AlgoPattern, 0, "", 0, 1, 2*math.Log2(10) + 6},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				pwAlgo:      testCase.pwAlgo,
+				minPassLen:  testCase.minPassLen,
+				maxRepeat:   testCase.maxRepeat,
+				maxSequence: testCase.maxSequence,
+				pwPattern:   "?d-?d?d?d",
+			}
+			entropy, err := getEntropy(&testConfig, testCase.charRange, nil)
+			if err != nil {
+				t.Fatalf("Entropy calculation failed: %v", err)
+			}
+			if math.Abs(entropy-testCase.expEntropy) > 0.0001 {
+				t.Errorf("Entropy calculation returned wrong value. Expected: %v, got: %v",
+					testCase.expEntropy, entropy)
+			}
+		})
+	}
+
+	t.Run("passphrase_uncounted", func(t *testing.T) {
+		testConfig := Config{pwAlgo: AlgoPassphrase, numOfWords: 4, maxRepeat: 1, minEntropy: 8}
+		if !hasUncountedLimits(&testConfig) {
+			t.Errorf("Repeat limit of passphrases is expected to be reported as not taken into account")
+		}
+		if err := applyMinEntropy(&testConfig, "", []string{"a", "b", "c", "d"}); err == nil {
+			t.Errorf("Applying minimum entropy to a passphrase with a repeat limit was expected to fail")
+		}
+	})
+}
+
+// Test applyMinEntropy() with different config settings
+func TestApplyMinEntropy(t *testing.T) {
+	testTable := []struct {
+		testName     string
+		pwAlgo       int
+		minPassLen   int
+		maxPassLen   int
+		minEntropy   float64
+		charRange    string
+		expMinLength int
+		shouldFail   bool
+	}{
+		{"disabled", AlgoRandom, 12, 20, 0, "0123456789abcdef", 12, false},
+		{"already_met", AlgoRandom, 12, 20, 48, "0123456789abcdef", 12, false},
+		{"raise_min_length", AlgoRandom, 12, 20, 64, "0123456789abcdef", 16, false},
+		{"raise_min_length_round_up", AlgoRandom, 12, 20, 65, "0123456789abcdef", 17, false},
+		{"max_length_exceeded", AlgoRandom, 12, 20, 81, "0123456789abcdef", 0, true},
+		{"min_length_above_max_length", AlgoRandom, 30, 20, 100, "0123456789abcdef", 30, false},
+		{"raise_koremutake_syllables", AlgoKoremutake, 2, 10, 35, "", 5, false},
+		{"pattern_too_weak", AlgoPattern, 0, 0, 20, "", 0, true},
+		{"pronounceable_unsupported", AlgoPronounceable, 12, 20, 10, "", 0, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				pwAlgo:     testCase.pwAlgo,
+				minPassLen: testCase.minPassLen,
+				maxPassLen: testCase.maxPassLen,
+				minEntropy: testCase.minEntropy,
+				pwPattern:  "?d?d?d?d",
+			}
+			err := applyMinEntropy(&testConfig, testCase.charRange, nil)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Fatalf("Applying minimum entropy succeeded but was expected to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Applying minimum entropy failed: %v", err)
+			}
+			if testConfig.minPassLen != testCase.expMinLength {
+				t.Errorf("Applying minimum entropy resulted in wrong minimum length. Expected: %d, got: %d",
+					testCase.expMinLength, testConfig.minPassLen)
+			}
+		})
+	}
+
+	t.Run("raise_min_length_for_first_char", func(t *testing.T) {
+		testConfig := Config{useLowerCase: true, useNumber: true, firstCharModes: "N", pwAlgo: AlgoRandom,
+			minPassLen: 1, maxPassLen: 20, minEntropy: 20}
+		if err := applyMinEntropy(&testConfig, getCharRange(&testConfig), nil); err != nil {
+			t.Fatalf("Applying minimum entropy failed: %v", err)
+		}
+		if testConfig.minPassLen != 5 {
+			t.Errorf("Applying minimum entropy resulted in wrong minimum length. Expected: 5, got: %d",
+				testConfig.minPassLen)
+		}
+	})
+}
+
+// Test getEdgeCharRange() and getPositionRange() with different character sets and lengths
+func TestEdgeChars(t *testing.T) {
+	config := Config{useLowerCase: true, useNumber: true, humanReadable: true,
+		ambiguousChars: DefaultAmbiguousChars}
+	if _, err := getEdgeCharRange(&config, "LX"); err == nil {
+		t.Errorf("Unknown character set was expected to fail")
+	}
+	letterRange, err := getEdgeCharRange(&config, "lu")
+	if err != nil {
+		t.Fatalf("getEdgeCharRange failed: %v", err)
+	}
+	if strings.ContainsAny(letterRange, "ilo"+PwUpperChars+PwNumbers) {
+		t.Errorf("Character range for lower and upper case contains unexpected characters: %q", letterRange)
+	}
+	numberRange, err := getEdgeCharRange(&config, "N")
+	if err != nil {
+		t.Fatalf("getEdgeCharRange failed: %v", err)
+	}
+
+	charRange := getCharRange(&config)
+	testTable := []struct {
+		testName string
+		pwEdges  edgeRanges
+		charPos  int
+		pwLength int
+		expRange string
+	}{
+		{"no_constraints", edgeRanges{}, 0, 4, charRange},
+		{"first_only", edgeRanges{firstRange: letterRange}, 0, 4, letterRange},
+		{"first_only_last_position", edgeRanges{firstRange: letterRange}, 3, 4, charRange},
+		{"last_only", edgeRanges{lastRange: numberRange}, 3, 4, numberRange},
+		{"inner_position", edgeRanges{letterRange, numberRange}, 1, 4, charRange},
+		{"two_chars_first", edgeRanges{letterRange, numberRange}, 0, 2, letterRange},
+		{"two_chars_last", edgeRanges{letterRange, numberRange}, 1, 2, numberRange},
+		{"single_char_both", edgeRanges{letterRange, letterRange + numberRange}, 0, 1, letterRange},
+		{"single_char_first_only", edgeRanges{firstRange: numberRange}, 0, 1, numberRange},
+		{"single_char_no_overlap", edgeRanges{letterRange, numberRange}, 0, 1, ""},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if posRange := getPositionRange(charRange, testCase.pwEdges, testCase.charPos,
+				testCase.pwLength); posRange != testCase.expRange {
+				t.Errorf("getPositionRange(%d, %d) returned %q, expected %q", testCase.charPos, testCase.pwLength,
+					posRange, testCase.expRange)
+			}
+		})
+	}
+
+	t.Run("random_password_edges", func(t *testing.T) {
+		pwEdges := edgeRanges{letterRange, numberRange}
+		for _, pwLength := range []int{2, 3, 12} {
+			for i := 0; i < 100; i++ {
+				pwString, err := getRandCharLimited(&charRange, pwLength, 0, 0, pwEdges)
+				if err != nil {
+					t.Fatalf("Random character generation failed: %v", err)
+				}
+				if !strings.ContainsAny(pwString[:1], letterRange) ||
+					!strings.ContainsAny(pwString[pwLength-1:], numberRange) {
+					t.Fatalf("Password %q does not start with a letter and end with a number", pwString)
+				}
+			}
+		}
+	})
+}
+
+// Test getEdgeRanges() and the random password generation with characters that passwords must
+// not start or end with
+func TestTrimSafeEdges(t *testing.T) {
+	testConfig := Config{useNumber: true, customChars: " \u00a0", trimUnsafeChars: DefaultTrimUnsafeChars}
+	pwEdges, err := getEdgeRanges(&testConfig)
+	if err != nil {
+		t.Fatalf("getEdgeRanges failed: %v", err)
+	}
+	if pwEdges.firstRange != PwNumbers || pwEdges.lastRange != PwNumbers {
+		t.Fatalf("Edge ranges are expected to only contain numbers, got: %q", pwEdges)
+	}
+	charRange := getCharRange(&testConfig)
+	charClasses := getCharClasses(&testConfig)
+
+	for _, pwLength := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("length_%d", pwLength), func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				pwString, err := getRandCharLimited(&charRange, pwLength, 0, 0, pwEdges)
+				if err != nil {
+					t.Fatalf("Random character generation failed: %v", err)
+				}
+				if !hasTrimSafeEdges(pwString, testConfig.trimUnsafeChars) {
+					t.Fatalf("Random password %q starts or ends with whitespace", pwString)
+				}
+				balancedString, err := getRandCharBalanced(pwLength+2, 0, 0, charClasses, pwEdges)
+				if err != nil {
+					t.Fatalf("Balanced password generation failed: %v", err)
+				}
+				if !hasTrimSafeEdges(balancedString, testConfig.trimUnsafeChars) {
+					t.Fatalf("Balanced password %q starts or ends with whitespace", balancedString)
+				}
+			}
+		})
+	}
+
+	t.Run("whitespace_only_in_the_middle", func(t *testing.T) {
+		spaceRange := "a "
+		for i := 0; i < 100; i++ {
+			pwString, err := getRandCharLimited(&spaceRange, 2, 0, 0, edgeRanges{"a", "a"})
+			if err != nil {
+				t.Fatalf("Random character generation failed: %v", err)
+			}
+			if pwString != "aa" {
+				t.Fatalf("Password of length 2 is expected to be \"aa\", got: %q", pwString)
+			}
+		}
+	})
+
+	t.Run("trim_unsafe_chars_disabled", func(t *testing.T) {
+		testConfig := Config{customChars: " a"}
+		pwEdges, err := getEdgeRanges(&testConfig)
+		if err != nil {
+			t.Fatalf("getEdgeRanges failed: %v", err)
+		}
+		if pwEdges != (edgeRanges{}) {
+			t.Errorf("Edge ranges are expected to be unrestricted, got: %q", pwEdges)
+		}
+	})
+
+	t.Run("no_trim_safe_chars", func(t *testing.T) {
+		testConfig := Config{useNumber: true, firstCharModes: "N", trimUnsafeChars: PwNumbers}
+		if _, err := getEdgeRanges(&testConfig); !errors.Is(err, ErrNoTrimSafeChars) {
+			t.Errorf("getEdgeRanges was expected to fail with %q, got: %v", ErrNoTrimSafeChars, err)
+		}
+	})
+}
+
+// Test hasTrimSafeEdges() with short passwords and different whitespace characters
+func TestHasTrimSafeEdges(t *testing.T) {
+	testTable := []struct {
+		testName    string
+		pwString    string
+		unsafeChars string
+		expResult   bool
+	}{
+		{"no_whitespace", "ab1", DefaultTrimUnsafeChars, true},
+		{"inner_whitespace", "a b", DefaultTrimUnsafeChars, true},
+		{"single_char", "a", DefaultTrimUnsafeChars, true},
+		{"single_space", " ", DefaultTrimUnsafeChars, false},
+		{"two_chars", "ab", DefaultTrimUnsafeChars, true},
+		{"two_chars_leading_space", " a", DefaultTrimUnsafeChars, false},
+		{"two_chars_trailing_space", "a ", DefaultTrimUnsafeChars, false},
+		{"trailing_newline", "ab\n", DefaultTrimUnsafeChars, false},
+		{"leading_no_break_space", "\u00a0ab", DefaultTrimUnsafeChars, false},
+		{"ideographic_space", "ab\u3000", DefaultTrimUnsafeChars, false},
+		{"custom_chars", "-ab", "-_", false},
+		{"custom_chars_inside", "a-b", "-_", true},
+		{"disabled", " a ", "", true},
+		{"empty_password", "", DefaultTrimUnsafeChars, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			if result := hasTrimSafeEdges(testCase.pwString, testCase.unsafeChars); result != testCase.expResult {
+				t.Errorf("hasTrimSafeEdges(%q) returned %v, expected %v", testCase.pwString, result,
+					testCase.expResult)
+			}
+		})
+	}
+
+	// Make sure that the default covers all whitespace characters
+	for curChar := rune(0); curChar <= unicode.MaxRune; curChar++ {
+		if unicode.IsSpace(curChar) != strings.ContainsRune(DefaultTrimUnsafeChars, curChar) {
+			t.Errorf("DefaultTrimUnsafeChars does not match unicode.IsSpace for %U", curChar)
+		}
+	}
+}
+
+// Test validateKeyspace() with different amounts of requested passwords
+func TestValidateKeyspace(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		pwAlgo     int
+		minPassLen int
+		numOfPass  int
+		shouldFail bool
+	}{
+		{"small_batch", AlgoRandom, 2, 10, false},
+		{"max_share", AlgoRandom, 2, 128, false},
+		{"share_exceeded", AlgoRandom, 2, 129, true},
+		{"longer_passwords", AlgoRandom, 3, 129, false},
+		{"pattern_max_share", AlgoPattern, 0, 5000, false},
+		{"pattern_exceeded", AlgoPattern, 0, 5001, true},
+		{"empty_word_list", AlgoPassphrase, 0, 1, true},
+		{"pronounceable_unchecked", AlgoPronounceable, 2, 100000, false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testConfig := Config{
+				pwAlgo:     testCase.pwAlgo,
+				minPassLen: testCase.minPassLen,
+				numOfPass:  testCase.numOfPass,
+				pwPattern:  "?d?d?d?d",
+			}
+			err := validateKeyspace(&testConfig, "0123456789abcdef", nil)
+			if testCase.shouldFail && err == nil {
+				t.Errorf("Keyspace validation succeeded but was expected to fail")
+			}
+			if !testCase.shouldFail && err != nil {
+				t.Errorf("Keyspace validation failed: %v", err)
+			}
+		})
+	}
+}
+
+// Test Conversions
+func TestConvert(t *testing.T) {
+	testTable := []struct {
+		testName   string
+		givenVal   byte
+		expVal     string
+		shouldFail bool
+	}{
+		{"convert_A_to_Alfa", 'A', "Alfa", false},
 		{"convert_a_to_alfa", 'a', "alfa", false},
 		{"convert_0_to_ZERO", '0', "ZERO", false},
 		{"convert_/_to_SLASH", '/', "SLASH", false},
@@ -194,7 +2155,7 @@ func TestConvert(t *testing.T) {
 
 	for _, testCase := range testTable {
 		t.Run(testCase.testName, func(t *testing.T) {
-			charToString, err := convertCharToName(testCase.givenVal)
+			charToString, err := convertCharToName(testCase.givenVal, alphabetNames)
 			if testCase.shouldFail {
 				if err == nil {
 					t.Errorf("Character to string conversion succeeded but was expected to fail. Given: %v, returned: %v",
@@ -220,7 +2181,7 @@ func TestConvert(t *testing.T) {
 		config.humanReadable = false
 		charRange := getCharRange(&config)
 		for _, curChar := range charRange {
-			_, err := convertCharToName(byte(curChar))
+			_, err := convertCharToName(byte(curChar), alphabetNames)
 			if err != nil {
 				t.Fatalf("Character to string conversion failed: %v", err.Error())
 			}
@@ -228,7 +2189,7 @@ func TestConvert(t *testing.T) {
 	})
 	t.Run("spell_Ab!_to_strings", func(t *testing.T) {
 		pwString := "Ab!"
-		spelledString, err := spellPasswordString(pwString)
+		spelledString, err := spellPasswordString(pwString, alphabetNames)
 		if err != nil {
 			t.Fatalf("password spelling failed: %v", err.Error())
 		}
@@ -238,6 +2199,177 @@ func TestConvert(t *testing.T) {
 				spelledString)
 		}
 	})
+	t.Run("spell_non_ascii_fails", func(t *testing.T) {
+		// 'ġ' is U+0121 and would be mistaken for '!' (0x21) if truncated to a byte
+		spelledString, err := spellPasswordString("Aġ", alphabetNames)
+		if err == nil {
+			t.Fatalf("Spelling non-ASCII pwString succeeded but was expected to fail. Returned: %q",
+				spelledString)
+		}
+	})
+}
+
+// Test parseBlockedSubstrings() and containsBlockedSubstring()
+func TestContainsBlockedSubstring(t *testing.T) {
+	blockedSubstrings := parseBlockedSubstrings("Pass, word,,bad ")
+	if len(blockedSubstrings) != 3 || blockedSubstrings[0] != "pass" || blockedSubstrings[1] != "word" ||
+		blockedSubstrings[2] != "bad" {
+		t.Fatalf("Blocked substrings were not parsed as expected, got: %q", blockedSubstrings)
+	}
+
+	testTable := []struct {
+		testName string
+		pwString string
+		expBlock bool
+	}{
+		{"no_blocked_substring", "x7Kq2mZ", false},
+		{"exact_match", "bad", true},
+		{"case_insensitive", "x7BaDq", true},
+		{"across_syllables", "bibadu", true},
+		{"no_word_boundaries", "xpassbx", true},
+		{"empty_password", "", false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			isBlocked := containsBlockedSubstring(testCase.pwString, blockedSubstrings)
+			if isBlocked != testCase.expBlock {
+				t.Errorf("Blocked substring check for %q is not as expected. Expected: %v, got: %v",
+					testCase.pwString, testCase.expBlock, isBlocked)
+			}
+		})
+	}
+
+	t.Run("empty_blocklist", func(t *testing.T) {
+		if containsBlockedSubstring("bad", nil) {
+			t.Errorf("Blocked substring check with empty blocklist was expected to succeed")
+		}
+	})
+}
+
+// Test parseContextString() and containsContextString() with l33t substitutions
+func TestContainsContextString(t *testing.T) {
+	contextStrings := parseContextString("JDoe@example.com")
+	expStrings := []string{"jdoeaexample.com", "jdoe", "example", "com"}
+	if len(contextStrings) != len(expStrings) {
+		t.Fatalf("Context strings were not parsed as expected. Expected: %q, got: %q", expStrings, contextStrings)
+	}
+	for i := range expStrings {
+		if contextStrings[i] != expStrings[i] {
+			t.Fatalf("Context strings were not parsed as expected. Expected: %q, got: %q", expStrings,
+				contextStrings)
+		}
+	}
+	if shortStrings := parseContextString("jo.li"); len(shortStrings) != 1 {
+		t.Errorf("Context string parts shorter than %d characters were expected to be ignored, got: %q",
+			MinContextStringLength, shortStrings)
+	}
+
+	testTable := []struct {
+		testName   string
+		pwString   string
+		expContext bool
+	}{
+		{"no_context_string", "x7Kq2mZ", false},
+		{"user_name", "x7jdoeZ", true},
+		{"user_name_upper_case", "x7JDOEZ", true},
+		{"user_name_leetspeak", "xJD03x", true},
+		{"domain_leetspeak", "3x4mp1e", true},
+		{"tld", "C0Mx", true},
+		{"interrupted_user_name", "j-doe", false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			hasContext := containsContextString(testCase.pwString, contextStrings)
+			if hasContext != testCase.expContext {
+				t.Errorf("Context string check for %q is not as expected. Expected: %v, got: %v",
+					testCase.pwString, testCase.expContext, hasContext)
+			}
+		})
+	}
+}
+
+// Test that every phonetic alphabet spells all characters unambiguously, so that the spelling
+// can be converted back into the password
+func TestPhoneticAlphabets(t *testing.T) {
+	testConfig := Config{useLowerCase: true, useUpperCase: true, useNumber: true, useSpecial: true}
+	charRange := getCharRange(&testConfig)
+	for _, alphabetName := range phoneticAlphabetNames {
+		t.Run(alphabetName, func(t *testing.T) {
+			letterNames, err := getPhoneticAlphabet(strings.ToUpper(alphabetName))
+			if err != nil {
+				t.Fatalf("Phonetic alphabet lookup failed: %v", err)
+			}
+			charNames := make(map[string]byte)
+			for _, curChar := range charRange {
+				charName, err := convertCharToName(byte(curChar), letterNames)
+				if err != nil {
+					t.Fatalf("Character to string conversion failed: %v", err)
+				}
+				if prevChar, ok := charNames[charName]; ok {
+					t.Fatalf("Characters %q and %q are both spelled as %q", prevChar, curChar, charName)
+				}
+				charNames[charName] = byte(curChar)
+			}
+
+			spelledString, err := spellPasswordString(charRange, letterNames)
+			if err != nil {
+				t.Fatalf("Password spelling failed: %v", err)
+			}
+			var pwString []byte
+			for _, charName := range strings.Split(spelledString, "/") {
+				pwString = append(pwString, charNames[charName])
+			}
+			if string(pwString) != charRange {
+				t.Errorf("Spelling could not be converted back. Expected: %q, got: %q", charRange, pwString)
+			}
+		})
+	}
+
+	t.Run("default_alphabet", func(t *testing.T) {
+		letterNames, err := getPhoneticAlphabet("")
+		if err != nil {
+			t.Fatalf("Phonetic alphabet lookup failed: %v", err)
+		}
+		if letterNames['A'] != "Alfa" {
+			t.Errorf("Default phonetic alphabet is expected to be NATO, got %q for 'A'", letterNames['A'])
+		}
+	})
+
+	t.Run("unknown_alphabet", func(t *testing.T) {
+		if _, err := getPhoneticAlphabet("klingon"); err == nil {
+			t.Errorf("Phonetic alphabet lookup was expected to fail for unknown alphabet")
+		}
+	})
+}
+
+// Test groupPasswordString() with different group lengths
+func TestGroupPasswordString(t *testing.T) {
+	testTable := []struct {
+		testName    string
+		pwString    string
+		groupLength int
+		separator   string
+		expString   string
+	}{
+		{"grouping_disabled", "4fj29dkq1mz8", 0, "-", "4fj29dkq1mz8"},
+		{"even_groups", "4fj29dkq1mz8", 4, "-", "4fj2-9dkq-1mz8"},
+		{"uneven_groups", "4fj29dkq1m", 4, "-", "4fj2-9dkq-1m"},
+		{"group_longer_than_password", "4fj2", 8, "-", "4fj2"},
+		{"custom_separator", "4fj29dkq", 4, " ", "4fj2 9dkq"},
+		{"multi_byte_chars", "äöüßäöüß", 4, "-", "äöüß-äöüß"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.testName, func(t *testing.T) {
+			pwString := groupPasswordString(testCase.pwString, testCase.groupLength, testCase.separator)
+			if pwString != testCase.expString {
+				t.Errorf("Grouped password is not as expected. Expected: %q, got: %q", testCase.expString,
+					pwString)
+			}
+		})
+	}
 }
 
 // Benchmark: Random number generation
@@ -267,8 +2399,86 @@ func BenchmarkConvertChar(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		charToConv, _ := getRandChar(&charRange, 1)
 		charBytes := []byte(charToConv)
-		_, _ = convertCharToName(charBytes[0])
+		_, _ = convertCharToName(charBytes[0], alphabetNames)
+	}
+}
+
+// Error returned by failReader
+var errFailReader = errors.New("failReader always fails")
+
+// Source of randomness that always fails on read
+type failReader struct{}
+
+func (failReader) Read([]byte) (int, error) {
+	return 0, errFailReader
+}
+
+// Generate the given amount of random characters per character of the given character range
+// and calculate the chi-square value of the distribution against the uniform distribution
+func getChiSquare(t *testing.T, charRange string, samplesPerChar int) float64 {
+	t.Helper()
+	rangeLength := utf8.RuneCountInString(charRange)
+	randChars, err := getRandChar(&charRange, rangeLength*samplesPerChar)
+	if err != nil {
+		t.Fatalf("Random character generation failed => %v", err.Error())
+	}
+	charCount := make(map[rune]int)
+	for _, curChar := range randChars {
+		charCount[curChar]++
+	}
+	var chiSquare float64
+	for _, curChar := range charRange {
+		deviation := float64(charCount[curChar] - samplesPerChar)
+		chiSquare += deviation * deviation / float64(samplesPerChar)
+	}
+	return chiSquare
+}
+
+// Decode the given koremutake string into the number it represents
+func koremutakeDecode(koremutakeString string) (*big.Int, error) {
+	if koremutakeString == "" {
+		err := fmt.Errorf("provided koremutake string is empty")
+		return nil, err
+	}
+	num := new(big.Int)
+	numOfSyllables := big.NewInt(int64(len(koremutakeSyllables)))
+	for _, curSyllable := range koremutakeSplit(strings.ToLower(koremutakeString)) {
+		syllableNum := -1
+		for i, koremutakeSyllable := range koremutakeSyllables {
+			if curSyllable == koremutakeSyllable {
+				syllableNum = i
+				break
+			}
+		}
+		if syllableNum < 0 {
+			err := fmt.Errorf("invalid koremutake syllable: %q", curSyllable)
+			return nil, err
+		}
+		num.Mul(num, numOfSyllables)
+		num.Add(num, big.NewInt(int64(syllableNum)))
+	}
+	return num, nil
+}
+
+// Contains function to search a given string slice for a value
+func containsString(allowedStrings []string, currentString string) bool {
+	for _, allowedString := range allowedStrings {
+		if allowedString == currentString {
+			return true
+		}
+	}
+	return false
+}
+
+// Write the given content to a temporary file and return the file name
+func writeTestFile(t *testing.T, fileContent string) string {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), "testfile.txt")
+	if err := os.WriteFile(fileName, []byte(fileContent), 0600); err != nil {
+		t.Fatalf("unable to write test file: %v", err)
 	}
+	return fileName
 }
 
 // Contains function to search a given slice for values
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Minimum length of the parts of a context string that are checked
+const MinContextStringLength int = 3
+
+// Common l33t substitutions that are reverted before context strings are matched
+var leetReplacer = strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a",
+	"$", "s")
+
+// Parse the given comma-separated list of blocked substrings. Blank entries are ignored
+func parseBlockedSubstrings(blockString string) []string {
+	var blockedSubstrings []string
+	for _, curSubstring := range strings.Split(blockString, ",") {
+		curSubstring = strings.ToLower(strings.TrimSpace(curSubstring))
+		if curSubstring == "" {
+			continue
+		}
+		blockedSubstrings = append(blockedSubstrings, curSubstring)
+	}
+	return blockedSubstrings
+}
+
+// Check if the given password contains any of the given blocked substrings. The check is
+// case-insensitive and does not care about word boundaries, since offensive words are
+// often the result of concatenated syllables or words
+func containsBlockedSubstring(pwString string, blockedSubstrings []string) bool {
+	lowerPwString := strings.ToLower(pwString)
+	for _, blockedSubstring := range blockedSubstrings {
+		if strings.Contains(lowerPwString, strings.ToLower(blockedSubstring)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse the given context string (i. e. a user name, an e-mail address or a service name) into
+// the normalized strings that passwords must not contain. Besides the whole string, every part
+// of it that is separated by non-alphanumeric characters (i. e. jdoe, example and com of
+// jdoe@example.com) is used. Strings shorter than MinContextStringLength are ignored
+func parseContextString(contextString string) []string {
+	contextParts := strings.FieldsFunc(contextString, func(curChar rune) bool {
+		return !unicode.IsLetter(curChar) && !unicode.IsNumber(curChar)
+	})
+	contextParts = append([]string{contextString}, contextParts...)
+
+	var contextStrings []string
+	seenParts := make(map[string]bool)
+	for _, curPart := range contextParts {
+		curPart = normalizeLeetspeak(strings.TrimSpace(curPart))
+		if len([]rune(curPart)) < MinContextStringLength || seenParts[curPart] {
+			continue
+		}
+		seenParts[curPart] = true
+		contextStrings = append(contextStrings, curPart)
+	}
+	return contextStrings
+}
+
+// Check if the given password contains any of the given (normalized) context strings, even
+// if they are disguised with simple l33t substitutions (i. e. jd03 for jdoe)
+func containsContextString(pwString string, contextStrings []string) bool {
+	return containsBlockedSubstring(normalizeLeetspeak(pwString), contextStrings)
+}
+
+// Normalize the given string to lower case and revert common l33t substitutions
+func normalizeLeetspeak(leetString string) string {
+	return leetReplacer.Replace(strings.ToLower(leetString))
+}
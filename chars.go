@@ -1,29 +1,70 @@
 package main
 
 import (
-	"regexp"
+	"fmt"
+	"strings"
 )
 
-const PwLowerCharsHuman string = "abcdefghjkmnpqrstuvwxyz"
-const PwUpperCharsHuman string = "ABCDEFGHJKMNPQRSTUVWXYZ"
 const PwLowerChars string = "abcdefghijklmnopqrstuvwxyz"
 const PwUpperChars string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-const PwSpecialCharsHuman string = "\"#%*+-/:;=\\_|~"
 const PwSpecialChars string = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
-const PwNumbersHuman string = "23456789"
 const PwNumbers string = "1234567890"
 
+// Special characters that are safe to use unencoded in URLs (RFC 3986 unreserved characters)
+// and unquoted in POSIX shells
+const PwSpecialCharsURLSafe string = "-._~"
+const PwSpecialCharsShellSafe string = "%+,-./:=@_"
+
+// Characters that are at the same key position with the same shift state on QWERTY (US),
+// QWERTZ (German) and AZERTY (French) keyboards. Numbers require shift on AZERTY keyboards and
+// no special character shares its key on all three layouts
+const PwLayoutSafeChars string = "bcdefghijklnoprstuvxBCDEFGHIJKLNOPRSTUVX"
+
+// Selectable sets of special characters
+var specialCharSets = map[string]string{
+	"default":   PwSpecialChars,
+	"urlsafe":   PwSpecialCharsURLSafe,
+	"shellsafe": PwSpecialCharsShellSafe,
+}
+var specialCharSetNames = []string{"default", "urlsafe", "shellsafe"}
+
+// Character classes that can be selected for the first and last character of a password
+var edgeCharModes = map[rune]string{
+	'L': "lower case",
+	'U': "upper case",
+	'N': "numeric",
+	'S': "special",
+}
+
+// Characters that are removed from the character classes in human-readable mode
+const DefaultAmbiguousChars string = "ILOilo01!$&'(),.<>?@[]^`{}"
+
+// Characters that passwords never start or end with, as many applications silently strip them
+// from the start and the end of their input (all unicode whitespace characters)
+const DefaultTrimUnsafeChars string = "\t\n\v\f\r \u0085\u00a0\u1680\u2000\u2001\u2002\u2003\u2004\u2005" +
+	"\u2006\u2007\u2008\u2009\u200a\u2028\u2029\u202f\u205f\u3000"
+
 // Provide the range of available characters based on provided parameters
 func getCharRange(config *Config) string {
 	pwUpperChars := PwUpperChars
 	pwLowerChars := PwLowerChars
 	pwNumbers := PwNumbers
 	pwSpecialChars := PwSpecialChars
+	if curSet, ok := specialCharSets[strings.ToLower(config.specialCharSet)]; ok {
+		pwSpecialChars = curSet
+	}
+	if config.layoutSafe {
+		pwUpperChars = filterCharRange(pwUpperChars, PwLayoutSafeChars)
+		pwLowerChars = filterCharRange(pwLowerChars, PwLayoutSafeChars)
+		pwNumbers = filterCharRange(pwNumbers, PwLayoutSafeChars)
+		pwSpecialChars = filterCharRange(pwSpecialChars, PwLayoutSafeChars)
+	}
 	if config.humanReadable {
-		pwUpperChars = PwUpperCharsHuman
-		pwLowerChars = PwLowerCharsHuman
-		pwNumbers = PwNumbersHuman
-		pwSpecialChars = PwSpecialCharsHuman
+		ambiguousChars := getAmbiguousChars(config)
+		pwUpperChars = cleanCharRange(pwUpperChars, ambiguousChars)
+		pwLowerChars = cleanCharRange(pwLowerChars, ambiguousChars)
+		pwNumbers = cleanCharRange(pwNumbers, ambiguousChars)
+		pwSpecialChars = cleanCharRange(pwSpecialChars, ambiguousChars)
 	}
 
 	var charRange string
@@ -39,10 +80,244 @@ func getCharRange(config *Config) string {
 	if config.useSpecial {
 		charRange = charRange + pwSpecialChars
 	}
-	if config.excludeChars != "" {
-		regExp := regexp.MustCompile("[" + regexp.QuoteMeta(config.excludeChars) + "]")
-		charRange = regExp.ReplaceAllLiteralString(charRange, "")
+	if config.customChars != "" {
+		charRange = charRange + config.customChars
+	}
+
+	return cleanCharRange(charRange, config.excludeChars)
+}
+
+// Make sure that the given name of a special character set is valid. An empty name selects
+// the default special characters
+func validateSpecialCharSet(setName string) error {
+	if _, ok := specialCharSets[strings.ToLower(setName)]; !ok && setName != "" {
+		err := fmt.Errorf("unknown special character set %q (valid values: %s)", setName,
+			strings.Join(specialCharSetNames, ", "))
+		return err
+	}
+	return nil
+}
+
+// Provide a config without any enabled character classes, that applies the same filters
+// (human readability, special character set and excluded characters) as the given config
+func getFilterConfig(config *Config) Config {
+	return Config{
+		humanReadable:  config.humanReadable,
+		ambiguousChars: config.ambiguousChars,
+		layoutSafe:     config.layoutSafe,
+		specialCharSet: config.specialCharSet,
+		excludeChars:   config.excludeChars,
+	}
+}
+
+// Provide the characters that are considered ambiguous in human-readable mode
+func getAmbiguousChars(config *Config) string {
+	if config.ambiguousChars == "" {
+		return DefaultAmbiguousChars
+	}
+	return config.ambiguousChars
+}
+
+// Remove all characters from the given character range that are not part of the given
+// allowed characters
+func filterCharRange(charRange, allowedChars string) string {
+	var filteredRange []rune
+	for _, curChar := range charRange {
+		if strings.ContainsRune(allowedChars, curChar) {
+			filteredRange = append(filteredRange, curChar)
+		}
+	}
+	return string(filteredRange)
+}
+
+// Remove the excluded characters and any duplicate characters from the given character
+// range, so that no character is more likely to be selected than the others
+func cleanCharRange(charRange, excludeChars string) string {
+	var cleanRange []rune
+	seenChars := make(map[rune]bool)
+	for _, curChar := range charRange {
+		if seenChars[curChar] || strings.ContainsRune(excludeChars, curChar) {
+			continue
+		}
+		seenChars[curChar] = true
+		cleanRange = append(cleanRange, curChar)
 	}
+	return string(cleanRange)
+}
+
+// Character class with the characters it provides after exclusions have been applied
+type charClass struct {
+	className string
+	charRange string
+}
+
+// Characters allowed at the first and the last position of a password. An empty range allows
+// every character of the character range
+type edgeRanges struct {
+	firstRange string
+	lastRange  string
+}
 
+// Provide the enabled character classes with their available characters
+func getCharClasses(config *Config) []charClass {
+	classConfigs := []struct {
+		className string
+		isEnabled bool
+		config    Config
+	}{
+		{"lower case", config.useLowerCase, Config{useLowerCase: true}},
+		{"upper case", config.useUpperCase, Config{useUpperCase: true}},
+		{"numeric", config.useNumber, Config{useNumber: true}},
+		{"special", config.useSpecial, Config{useSpecial: true}},
+		{"custom", config.customChars != "", Config{customChars: config.customChars}},
+	}
+	var charClasses []charClass
+	for _, classConfig := range classConfigs {
+		if !classConfig.isEnabled {
+			continue
+		}
+		classConfig.config.humanReadable = config.humanReadable
+		classConfig.config.ambiguousChars = config.ambiguousChars
+		classConfig.config.specialCharSet = config.specialCharSet
+		classConfig.config.layoutSafe = config.layoutSafe
+		classConfig.config.excludeChars = config.excludeChars
+		charClasses = append(charClasses, charClass{classConfig.className, getCharRange(&classConfig.config)})
+	}
+	return charClasses
+}
+
+// Make sure that every enabled character class still provides at least one character
+// after the excluded characters have been removed from the character range
+func validateCharRange(config *Config) error {
+	for _, curClass := range getCharClasses(config) {
+		if curClass.charRange != "" {
+			continue
+		}
+		excludeReason := fmt.Sprintf("%q", config.excludeChars)
+		if config.humanReadable {
+			excludeReason = excludeReason + fmt.Sprintf(" and the ambiguous characters %q",
+				getAmbiguousChars(config))
+		}
+		if config.layoutSafe {
+			excludeReason = excludeReason + " and the characters that differ between keyboard layouts"
+		}
+		err := fmt.Errorf("no %s characters left in character range after excluding %s",
+			curClass.className, excludeReason)
+		return err
+	}
+	return nil
+}
+
+// Check if the given password contains at least one character of every given character class
+func hasAllCharClasses(pwString string, charClasses []charClass) bool {
+	for _, curClass := range charClasses {
+		if !strings.ContainsAny(pwString, curClass.charRange) {
+			return false
+		}
+	}
+	return true
+}
+
+// Provide the range of characters allowed at the first or last position of a password based
+// on the given character class letters (L, U, N and S, case-insensitive). Only classes that are
+// enabled in the config are used. An empty string is returned if no classes are given
+func getEdgeCharRange(config *Config, charModes string) (string, error) {
+	if charModes == "" {
+		return "", nil
+	}
+	selectedClasses := make(map[string]bool)
+	for _, curMode := range strings.ToUpper(charModes) {
+		className, ok := edgeCharModes[curMode]
+		if !ok {
+			err := fmt.Errorf("unknown character set %q (valid values: L, U, N, S)", curMode)
+			return "", err
+		}
+		selectedClasses[className] = true
+	}
+	var edgeRange string
+	for _, curClass := range getCharClasses(config) {
+		if selectedClasses[curClass.className] {
+			edgeRange = edgeRange + curClass.charRange
+		}
+	}
+	if edgeRange == "" {
+		err := fmt.Errorf("%w: none of the character sets %q is enabled", ErrNoEdgeChars, charModes)
+		return "", err
+	}
+	return edgeRange, nil
+}
+
+// Provide the ranges of characters allowed at the first and the last position of random
+// passwords: the character sets selected with -F and -J (or the whole character range) without
+// the characters that passwords must not start or end with
+func getEdgeRanges(config *Config) (edgeRanges, error) {
+	firstRange, err := getEdgeCharRange(config, config.firstCharModes)
+	if err != nil {
+		return edgeRanges{}, fmt.Errorf("invalid character sets for the first character: %w", err)
+	}
+	lastRange, err := getEdgeCharRange(config, config.lastCharModes)
+	if err != nil {
+		return edgeRanges{}, fmt.Errorf("invalid character sets for the last character: %w", err)
+	}
+	charRange := getCharRange(config)
+	firstRange, err = getTrimSafeRange(charRange, firstRange, config.trimUnsafeChars)
+	if err != nil {
+		return edgeRanges{}, fmt.Errorf("invalid first character: %w", err)
+	}
+	lastRange, err = getTrimSafeRange(charRange, lastRange, config.trimUnsafeChars)
+	if err != nil {
+		return edgeRanges{}, fmt.Errorf("invalid last character: %w", err)
+	}
+	return edgeRanges{firstRange: firstRange, lastRange: lastRange}, nil
+}
+
+// Remove the characters that passwords must not start or end with from the given edge range.
+// An empty edge range allows the whole character range, so it is only replaced if the
+// character range contains any of these characters
+func getTrimSafeRange(charRange, edgeRange, unsafeChars string) (string, error) {
+	if edgeRange == "" {
+		if !strings.ContainsAny(charRange, unsafeChars) {
+			return "", nil
+		}
+		edgeRange = charRange
+	}
+	safeRange := cleanCharRange(edgeRange, unsafeChars)
+	if safeRange == "" {
+		err := fmt.Errorf("%w: passwords must not start or end with any of the characters %q",
+			ErrNoTrimSafeChars, edgeRange)
+		return "", err
+	}
+	return safeRange, nil
+}
+
+// Provide the range of characters allowed at the given position of a password with the given
+// length. The character of a single character password needs to match both edge ranges
+func getPositionRange(charRange string, pwEdges edgeRanges, charPos, pwLength int) string {
+	firstRange, lastRange := charRange, charRange
+	if pwEdges.firstRange != "" {
+		firstRange = pwEdges.firstRange
+	}
+	if pwEdges.lastRange != "" {
+		lastRange = pwEdges.lastRange
+	}
+	switch {
+	case pwLength == 1:
+		return filterCharRange(firstRange, lastRange)
+	case charPos == 0:
+		return firstRange
+	case charPos == pwLength-1:
+		return lastRange
+	}
 	return charRange
 }
+
+// Check if the given password neither starts nor ends with one of the given characters. This
+// is only required for words and syllables, as single characters are never placed at the edges
+func hasTrimSafeEdges(pwString, unsafeChars string) bool {
+	pwRunes := []rune(pwString)
+	if len(pwRunes) == 0 {
+		return true
+	}
+	return !strings.ContainsRune(unsafeChars, pwRunes[0]) &&
+		!strings.ContainsRune(unsafeChars, pwRunes[len(pwRunes)-1])
+}
@@ -1,10 +1,44 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
+// Errors returned by validateConfig()
+var (
+	ErrNoModesSet       = errors.New("no password mode set")
+	ErrInvalidLength    = errors.New("invalid length")
+	ErrLengthTooLarge   = errors.New("length too large")
+	ErrUnknownAlgorithm = errors.New("unknown password generation algorithm")
+	ErrControlChar      = errors.New("control character in parameter")
+	ErrInvalidEntropy   = errors.New("invalid minimum entropy")
+	ErrNoTrimSafeChars  = errors.New("no characters left for the start or end of passwords")
+	ErrNoEdgeChars      = errors.New("no characters available for the first or last character")
+	ErrNoSyllableChars  = errors.New("not enough letters for pronounceable syllables")
+)
+
+// Names of the password generation algorithms (indexed by algorithm)
+var algoNames = []string{
+	AlgoPronounceable: "pronounceable",
+	AlgoRandom:        "random",
+	AlgoPassphrase:    "passphrase",
+	AlgoPattern:       "pattern",
+	AlgoKoremutake:    "koremutake",
+}
+
+// Distributions of the password length between the minimum and the maximum length
+const LengthUniform string = "uniform"
+const LengthPreferMax string = "prefermax"
+const LengthPreferMin string = "prefermin"
+
+var lengthDistNames = []string{LengthUniform, LengthPreferMax, LengthPreferMin}
+
 // Parse the CLI flags
 func parseFlags() Config {
 	var switchConf Config
@@ -23,6 +57,7 @@ func parseFlags() Config {
 		useSpecial:    defaultSwitches.useSpecial,
 		useComplex:    defaultSwitches.useComplex,
 		humanReadable: defaultSwitches.humanReadable,
+		pwAlgo:        AlgoRandom,
 	}
 
 	// Read and set all flags
@@ -32,13 +67,63 @@ func parseFlags() Config {
 	flag.BoolVar(&switchConf.useSpecial, "S", false, "Use special characters in passwords")
 	flag.BoolVar(&switchConf.useComplex, "C", false, "Generate complex passwords (implies -L -U -N -S, disables -H)")
 	flag.BoolVar(&switchConf.humanReadable, "H", false, "Generate human-readable passwords")
+	flag.BoolVar(&config.layoutSafe, "Y", false, "Only use characters that are the same on all keyboard layouts")
+	flag.BoolVar(&config.requireAllModes, "k", false,
+		"Require at least one character of every enabled character set in passwords")
+	flag.BoolVar(&config.balancedClasses, "B", false, "Use every enabled character set equally often in passwords")
+	flag.StringVar(&config.firstCharModes, "F", "", "Character sets allowed for the first character of passwords")
+	flag.StringVar(&config.lastCharModes, "J", "", "Character sets allowed for the last character of passwords")
+	flag.StringVar(&config.trimUnsafeChars, "I", DefaultTrimUnsafeChars,
+		"Characters that passwords must not start or end with")
 	flag.BoolVar(&config.spellPassword, "l", false, "Spell generated password")
+	flag.Func("T", "Syllable templates for pronounceable passwords", func(templateString string) error {
+		config.syllableTemplates = append(config.syllableTemplates, parseSyllableTemplates(templateString)...)
+		return nil
+	})
+	flag.BoolVar(&config.showSyllables, "o", false, "Show the syllables of pronounceable passwords")
+	flag.StringVar(&config.phoneticAlphabet, "t", DefaultPhoneticAlphabet,
+		"Phonetic alphabet for the password spelling")
+	flag.BoolVar(&config.cryptPassword, "y", false, "Print the SHA512-crypt hash of the generated password")
 	flag.BoolVar(&config.checkHibp, "p", false, "Check the HIBP database if the generated password was leaked before")
+	flag.BoolVar(&config.showEntropy, "e", false, "Show the entropy of the generated passwords")
+	flag.Float64Var(&config.minEntropy, "b", 0, "Minimum entropy of the generated passwords")
 	flag.BoolVar(&config.showVersion, "v", false, "Show version")
+	flag.Func("a", "Password generation algorithm", func(algoString string) error {
+		pwAlgo, err := parseAlgorithm(algoString)
+		config.pwAlgo = pwAlgo
+		return err
+	})
 	flag.IntVar(&config.minPassLen, "m", DefaultMinLenght, "Minimum password length")
 	flag.IntVar(&config.maxPassLen, "x", DefaultMaxLenght, "Maxiumum password length")
+	flag.StringVar(&config.lengthDist, "d", LengthUniform,
+		"Distribution of the password length between minimum and maximum length")
 	flag.IntVar(&config.numOfPass, "n", 6, "Number of passwords to generate")
+	flag.BoolVar(&config.uniquePasswords, "D", false, "Make sure that all generated passwords are distinct")
+	flag.IntVar(&config.maxRepeat, "R", 0, "Maximum amount of identical characters in a row")
+	flag.IntVar(&config.maxSequence, "Q", 0, "Maximum length of character sequences")
+	flag.IntVar(&config.maxKeyboardWalk, "K", 0, "Maximum length of keyboard walks")
 	flag.StringVar(&config.excludeChars, "E", "", "Exclude list of characters from generated password")
+	flag.StringVar(&config.specialCharSet, "z", "default", "Set of special characters")
+	flag.StringVar(&config.ambiguousChars, "A", DefaultAmbiguousChars,
+		"List of characters that are considered ambiguous in human-readable mode")
+	flag.StringVar(&config.customChars, "c", "", "Add list of custom characters to the character set")
+	flag.StringVar(&config.wordListFile, "r", "", "Word list file for passphrase generation")
+	flag.IntVar(&config.numOfWords, "W", DefaultNumOfWords, "Number of words in a generated passphrase")
+	flag.StringVar(&config.wordSeparator, "s", DefaultWordSeparator,
+		"Separator for the words of a passphrase or the groups of a grouped password")
+	flag.StringVar(&config.separatorSet, "w", "", "Set of characters to randomly select the passphrase separators from")
+	flag.BoolVar(&config.injectChars, "i", false,
+		"Inject a random number and/or special character into generated passphrases")
+	flag.IntVar(&config.groupLength, "g", 0, "Print generated passwords in groups of the given length")
+	flag.StringVar(&config.pwPattern, "P", "", "Pattern for pattern based password generation")
+	flag.Func("X", "Blocked substrings in passwords", func(blockString string) error {
+		config.blockedSubstrings = append(config.blockedSubstrings, parseBlockedSubstrings(blockString)...)
+		return nil
+	})
+	flag.Func("u", "Context strings in passwords", func(contextString string) error {
+		config.contextStrings = append(config.contextStrings, parseContextString(contextString)...)
+		return nil
+	})
 	flag.StringVar(&config.newStyleModes, "M", "",
 		"New style password parameters (higher priority than single parameters)")
 	flag.Parse()
@@ -82,11 +167,8 @@ func parseParams(config *Config) {
 		config.humanReadable = false
 	}
 
-	if config.useUpperCase == false &&
-		config.useLowerCase == false &&
-		config.useNumber == false &&
-		config.useSpecial == false {
-		log.Fatalf("No password mode set. Cannot generate password from empty character set.")
+	if err := validateConfig(config); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
 
 	// Set output mode
@@ -95,17 +177,222 @@ func parseParams(config *Config) {
 	}
 }
 
-// Get the password length from the given cli flags
+// Parse the given password generation algorithm (either its number or its name)
+func parseAlgorithm(algoString string) (int, error) {
+	algoString = strings.ToLower(strings.TrimSpace(algoString))
+	for pwAlgo, algoName := range algoNames {
+		if algoString == algoName || algoString == strconv.Itoa(pwAlgo) {
+			return pwAlgo, nil
+		}
+	}
+
+	var validValues []string
+	for pwAlgo, algoName := range algoNames {
+		validValues = append(validValues, fmt.Sprintf("%d/%s", pwAlgo, algoName))
+	}
+	return 0, fmt.Errorf("%w: %q (valid values: %s)", ErrUnknownAlgorithm, algoString,
+		strings.Join(validValues, ", "))
+}
+
+// Return the name of the given password generation algorithm
+func getAlgorithmName(pwAlgo int) string {
+	if pwAlgo < 0 || pwAlgo >= len(algoNames) {
+		return fmt.Sprintf("unknown(%d)", pwAlgo)
+	}
+	return algoNames[pwAlgo]
+}
+
+// Validate the given config and return an error if it cannot be used to generate passwords
+func validateConfig(config *Config) error {
+	if !config.useUpperCase &&
+		!config.useLowerCase &&
+		!config.useNumber &&
+		!config.useSpecial &&
+		config.customChars == "" {
+		return fmt.Errorf("%w: cannot generate password from empty character set", ErrNoModesSet)
+	}
+	// Make sure that no control characters end up in the generated passwords
+	userChars := []struct {
+		paramName  string
+		paramChars string
+	}{
+		{"custom characters", config.customChars},
+		{"word separator", config.wordSeparator},
+		{"separator set", config.separatorSet},
+		{"password pattern", config.pwPattern},
+	}
+	for _, curParam := range userChars {
+		if i := strings.IndexFunc(curParam.paramChars, unicode.IsControl); i >= 0 {
+			return fmt.Errorf("%w: %q at offset %d of the %s", ErrControlChar,
+				[]rune(curParam.paramChars[i:])[0], i, curParam.paramName)
+		}
+	}
+	if config.minPassLen < 0 {
+		return fmt.Errorf("%w: minimum password length is negative: %d", ErrInvalidLength, config.minPassLen)
+	}
+	if config.maxPassLen < 0 {
+		return fmt.Errorf("%w: maximum password length is negative: %d", ErrInvalidLength, config.maxPassLen)
+	}
+	if config.minPassLen > MaxPassLength {
+		return fmt.Errorf("%w: minimum password length exceeds the maximum supported length of %d: %d",
+			ErrLengthTooLarge, MaxPassLength, config.minPassLen)
+	}
+	if config.maxPassLen > MaxPassLength {
+		return fmt.Errorf("%w: maximum password length exceeds the maximum supported length of %d: %d",
+			ErrLengthTooLarge, MaxPassLength, config.maxPassLen)
+	}
+	if config.numOfWords > MaxPassLength {
+		return fmt.Errorf("%w: amount of words exceeds the maximum supported length of %d: %d",
+			ErrLengthTooLarge, MaxPassLength, config.numOfWords)
+	}
+	if config.maxRepeat < 0 {
+		return fmt.Errorf("%w: maximum amount of identical characters in a row is negative: %d",
+			ErrInvalidLength, config.maxRepeat)
+	}
+	if config.maxSequence < 0 {
+		return fmt.Errorf("%w: maximum length of character sequences is negative: %d",
+			ErrInvalidLength, config.maxSequence)
+	}
+	if config.groupLength < 0 {
+		return fmt.Errorf("%w: password group length is negative: %d", ErrInvalidLength, config.groupLength)
+	}
+	if err := validateSpecialCharSet(config.specialCharSet); err != nil {
+		return err
+	}
+	if err := validateLengthDistribution(config.lengthDist); err != nil {
+		return err
+	}
+	if err := validateSyllableTemplates(config.syllableTemplates); err != nil {
+		return err
+	}
+	if _, err := getPhoneticAlphabet(config.phoneticAlphabet); err != nil {
+		return err
+	}
+	if config.maxKeyboardWalk < 0 {
+		return fmt.Errorf("%w: maximum length of keyboard walks is negative: %d", ErrInvalidLength,
+			config.maxKeyboardWalk)
+	}
+	if config.minEntropy < 0 {
+		return fmt.Errorf("%w: minimum entropy (-b) is negative: %v", ErrInvalidEntropy, config.minEntropy)
+	}
+	if (config.requireAllModes || config.balancedClasses) && config.pwAlgo == AlgoRandom {
+		numOfClasses := len(getCharClasses(config))
+		if config.minPassLen < numOfClasses {
+			return fmt.Errorf("%w: minimum password length %d is too small for %d required character sets",
+				ErrInvalidLength, config.minPassLen, numOfClasses)
+		}
+	}
+	if config.pwAlgo == AlgoRandom {
+		pwEdges, err := getEdgeRanges(config)
+		if err != nil {
+			return err
+		}
+		if config.minPassLen <= 1 && pwEdges.firstRange != "" && pwEdges.lastRange != "" &&
+			!strings.ContainsAny(pwEdges.firstRange, pwEdges.lastRange) {
+			return fmt.Errorf("%w: the character sets for the first and the last character do not overlap, "+
+				"but passwords can be a single character long", ErrNoEdgeChars)
+		}
+		if err := validateEdgeClasses(config, pwEdges); err != nil {
+			return err
+		}
+	}
+	switch config.pwAlgo {
+	case AlgoRandom, AlgoKoremutake:
+	case AlgoPronounceable:
+		if len(getSyllableSets(config)) == 0 {
+			return fmt.Errorf("%w: the character set does not provide the consonants or vowels of the "+
+				"syllable templates %q", ErrNoSyllableChars, getSyllableTemplates(config))
+		}
+	case AlgoPassphrase:
+		if config.numOfWords <= 0 {
+			return fmt.Errorf("%w: amount of words in passphrase is <= 0: %d", ErrInvalidLength,
+				config.numOfWords)
+		}
+		if config.injectChars && config.numOfWords == 1 {
+			for _, injectRange := range getInjectRanges(config) {
+				if cleanCharRange(injectRange, config.trimUnsafeChars) == "" {
+					return fmt.Errorf("%w: a single word passphrase must not start or end with any of the "+
+						"injected characters %q", ErrNoTrimSafeChars, injectRange)
+				}
+			}
+		}
+	case AlgoPattern:
+		if _, err := parsePattern(config); err != nil {
+			return fmt.Errorf("invalid password pattern: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: %d", ErrUnknownAlgorithm, config.pwAlgo)
+	}
+	return nil
+}
+
+// Make sure that random passwords of every possible length can contain all required character
+// sets (-k, -B) and still start and end with one of the allowed characters. From a length of
+// twice the amount of character sets on, the password length makes no difference anymore
+func validateEdgeClasses(config *Config, pwEdges edgeRanges) error {
+	if (!config.requireAllModes && !config.balancedClasses) || pwEdges == (edgeRanges{}) {
+		return nil
+	}
+	charClasses := getCharClasses(config)
+	charRange := getCharRange(config)
+	maxLength := config.maxPassLen
+	if maxLength < config.minPassLen {
+		maxLength = config.minPassLen
+	}
+	if maxLength > config.minPassLen+2*len(charClasses) {
+		maxLength = config.minPassLen + 2*len(charClasses)
+	}
+	for pwLength := config.minPassLen; pwLength <= maxLength; pwLength++ {
+		var ok bool
+		if config.balancedClasses {
+			_, ok = calcBalancedEntropy(pwLength, charClasses, pwEdges.firstRange, pwEdges.lastRange)
+		} else {
+			_, ok = calcRandomEntropy(pwLength, charRange, pwEdges.firstRange, pwEdges.lastRange, charClasses)
+		}
+		if !ok {
+			return fmt.Errorf("%w: passwords with a length of %d cannot contain all required character sets "+
+				"and start and end with the allowed characters", ErrNoEdgeChars, pwLength)
+		}
+	}
+	return nil
+}
+
+// Make sure the given length distribution is supported
+func validateLengthDistribution(distName string) error {
+	for _, curName := range lengthDistNames {
+		if strings.ToLower(distName) == curName {
+			return nil
+		}
+	}
+	if distName == "" {
+		return nil
+	}
+	err := fmt.Errorf("unknown length distribution %q (valid values: %s)", distName,
+		strings.Join(lengthDistNames, ", "))
+	return err
+}
+
+// Get the password length from the given cli flags. With a length distribution that prefers
+// the maximum (or minimum) length, the longer (or shorter) of two random lengths is used
 func getPwLengthFromParams(config *Config) int {
 	if config.minPassLen > config.maxPassLen {
 		config.maxPassLen = config.minPassLen
 	}
-	lenDiff := config.maxPassLen - config.minPassLen + 1
-	randAdd, err := getRandNum(lenDiff)
+	retVal, err := getRandNumBetween(config.minPassLen, config.maxPassLen)
 	if err != nil {
 		log.Fatalf("Failed to generated password length: %v", err)
 	}
-	retVal := config.minPassLen + randAdd
+	distName := strings.ToLower(config.lengthDist)
+	if distName == LengthPreferMax || distName == LengthPreferMin {
+		secondVal, err := getRandNumBetween(config.minPassLen, config.maxPassLen)
+		if err != nil {
+			log.Fatalf("Failed to generated password length: %v", err)
+		}
+		if (distName == LengthPreferMax && secondVal > retVal) ||
+			(distName == LengthPreferMin && secondVal < retVal) {
+			retVal = secondVal
+		}
+	}
 	if retVal <= 0 {
 		return 1
 	}
@@ -78,12 +78,99 @@ var (
 		'Y': "Yankee",
 		'Z': "Zulu",
 	}
+	dinAlphabetNames = map[byte]string{
+		'A': "Aachen",
+		'B': "Berlin",
+		'C': "Chemnitz",
+		'D': "Düsseldorf",
+		'E': "Essen",
+		'F': "Frankfurt",
+		'G': "Goslar",
+		'H': "Hamburg",
+		'I': "Ingelheim",
+		'J': "Jena",
+		'K': "Köln",
+		'L': "Leipzig",
+		'M': "München",
+		'N': "Nürnberg",
+		'O': "Offenbach",
+		'P': "Potsdam",
+		'Q': "Quickborn",
+		'R': "Rostock",
+		'S': "Salzwedel",
+		'T': "Tübingen",
+		'U': "Unna",
+		'V': "Völklingen",
+		'W': "Wuppertal",
+		'X': "Xanten",
+		'Y': "Ypsilon",
+		'Z': "Zwickau",
+	}
+	janAlphabetNames = map[byte]string{
+		'A': "Able",
+		'B': "Baker",
+		'C': "Charlie",
+		'D': "Dog",
+		'E': "Easy",
+		'F': "Fox",
+		'G': "George",
+		'H': "How",
+		'I': "Item",
+		'J': "Jig",
+		'K': "King",
+		'L': "Love",
+		'M': "Mike",
+		'N': "Nan",
+		'O': "Oboe",
+		'P': "Peter",
+		'Q': "Queen",
+		'R': "Roger",
+		'S': "Sugar",
+		'T': "Tare",
+		'U': "Uncle",
+		'V': "Victor",
+		'W': "William",
+		'X': "X_ray",
+		'Y': "Yoke",
+		'Z': "Zebra",
+	}
+
+	// Phonetic alphabets for the password spelling (NATO, DIN 5009:2022 and Joint Army/Navy)
+	phoneticAlphabets = map[string]map[byte]string{
+		"nato":    alphabetNames,
+		"din5009": dinAlphabetNames,
+		"jan":     janAlphabetNames,
+	}
+	phoneticAlphabetNames = []string{"nato", "din5009", "jan"}
 )
 
-func spellPasswordString(pwString string) (string, error) {
+// Default phonetic alphabet for the password spelling
+const DefaultPhoneticAlphabet string = "nato"
+
+// Get the letter names of the phonetic alphabet with the given name (case-insensitive). An
+// empty name selects the default phonetic alphabet
+func getPhoneticAlphabet(alphabetName string) (map[byte]string, error) {
+	if alphabetName == "" {
+		alphabetName = DefaultPhoneticAlphabet
+	}
+	letterNames, ok := phoneticAlphabets[strings.ToLower(alphabetName)]
+	if !ok {
+		err := fmt.Errorf("unknown phonetic alphabet %q (valid values: %s)", alphabetName,
+			strings.Join(phoneticAlphabetNames, ", "))
+		return nil, err
+	}
+	return letterNames, nil
+}
+
+// Spell the given password with the given letter names of a phonetic alphabet
+func spellPasswordString(pwString string, letterNames map[byte]string) (string, error) {
 	var returnString []string
 	for _, curChar := range pwString {
-		curSpellString, err := convertCharToName(byte(curChar))
+		if curChar > 127 {
+			err := fmt.Errorf("cannot convert to character to name: %q is not an ASCII character", curChar)
+			return "", err
+		}
+		curSpellString, err := convertCharToName(byte(curChar), letterNames)
 		if err != nil {
 			return "", err
 		}
@@ -92,12 +179,13 @@ func spellPasswordString(pwString string) (string, error) {
 	return strings.Join(returnString, "/"), nil
 }
 
-func convertCharToName(charByte byte) (string, error) {
+// Convert the given character to its name with the given letter names of a phonetic alphabet
+func convertCharToName(charByte byte, letterNames map[byte]string) (string, error) {
 	var returnString string
 	if charByte > 64 && charByte < 91 {
-		returnString = alphabetNames[charByte]
+		returnString = letterNames[charByte]
 	} else if charByte > 96 && charByte < 123 {
-		returnString = strings.ToLower(alphabetNames[charByte-32])
+		returnString = strings.ToLower(letterNames[charByte-32])
 	} else {
 		returnString = symbNumNames[charByte]
 	}
@@ -108,3 +196,19 @@ func convertCharToName(charByte byte) (string, error) {
 	}
 	return returnString, nil
 }
+
+// Split the given password into groups of the given amount of characters, separated by the
+// given separator (i. e. 4fj2-9dkq-1mz8). A group length of 0 returns the password unchanged
+func groupPasswordString(pwString string, groupLength int, separator string) string {
+	pwRunes := []rune(pwString)
+	if groupLength <= 0 || len(pwRunes) <= groupLength {
+		return pwString
+	}
+	var pwGroups []string
+	for len(pwRunes) > groupLength {
+		pwGroups = append(pwGroups, string(pwRunes[:groupLength]))
+		pwRunes = pwRunes[groupLength:]
+	}
+	pwGroups = append(pwGroups, string(pwRunes))
+	return strings.Join(pwGroups, separator)
+}
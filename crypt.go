@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Settings for the SHA512-crypt password hashing
+const CryptSaltChars string = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+const CryptSaltLength int = 16
+const CryptDefaultRounds int = 5000
+const CryptMinRounds int = 1000
+const CryptMaxRounds int = 999999999
+
+// Byte order of the SHA512-crypt base64 encoding
+var cryptByteOrder = [][3]int{
+	{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+	{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+	{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+}
+
+// Generate the SHA512-crypt hash (as used in /etc/shadow) of the given password with a
+// random salt
+func getCryptHash(pwString string) (string, error) {
+	saltChars := CryptSaltChars
+	salt, err := getRandChar(&saltChars, CryptSaltLength)
+	if err != nil {
+		return "", err
+	}
+	return sha512Crypt(pwString, salt, CryptDefaultRounds)
+}
+
+// Calculate the SHA512-crypt hash of the given password with the given salt and rounds
+// as specified in https://www.akkadia.org/drepper/SHA-crypt.txt
+func sha512Crypt(pwString string, salt string, numOfRounds int) (string, error) {
+	if numOfRounds < CryptMinRounds || numOfRounds > CryptMaxRounds {
+		err := fmt.Errorf("provided numOfRounds is out of range (%d to %d): %d", CryptMinRounds,
+			CryptMaxRounds, numOfRounds)
+		return "", err
+	}
+	if len(salt) > CryptSaltLength {
+		salt = salt[:CryptSaltLength]
+	}
+	password := []byte(pwString)
+	saltBytes := []byte(salt)
+
+	altHash := sha512.New()
+	altHash.Write(password)
+	altHash.Write(saltBytes)
+	altHash.Write(password)
+	altSum := altHash.Sum(nil)
+
+	intHash := sha512.New()
+	intHash.Write(password)
+	intHash.Write(saltBytes)
+	for i := len(password); i > 0; i -= sha512.Size {
+		if i > sha512.Size {
+			intHash.Write(altSum)
+			continue
+		}
+		intHash.Write(altSum[:i])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			intHash.Write(altSum)
+			continue
+		}
+		intHash.Write(password)
+	}
+	intSum := intHash.Sum(nil)
+
+	pHash := sha512.New()
+	for i := 0; i < len(password); i++ {
+		pHash.Write(password)
+	}
+	pSeq := repeatBytes(pHash.Sum(nil), len(password))
+
+	sHash := sha512.New()
+	for i := 0; i < 16+int(intSum[0]); i++ {
+		sHash.Write(saltBytes)
+	}
+	sSeq := repeatBytes(sHash.Sum(nil), len(saltBytes))
+
+	for i := 0; i < numOfRounds; i++ {
+		roundHash := sha512.New()
+		if i&1 != 0 {
+			roundHash.Write(pSeq)
+		} else {
+			roundHash.Write(intSum)
+		}
+		if i%3 != 0 {
+			roundHash.Write(sSeq)
+		}
+		if i%7 != 0 {
+			roundHash.Write(pSeq)
+		}
+		if i&1 != 0 {
+			roundHash.Write(intSum)
+		} else {
+			roundHash.Write(pSeq)
+		}
+		intSum = roundHash.Sum(nil)
+	}
+
+	var cryptString strings.Builder
+	cryptString.WriteString("$6$")
+	if numOfRounds != CryptDefaultRounds {
+		cryptString.WriteString("rounds=" + strconv.Itoa(numOfRounds) + "$")
+	}
+	cryptString.WriteString(salt + "$")
+	for _, byteOrder := range cryptByteOrder {
+		cryptBase64(&cryptString, uint(intSum[byteOrder[0]])<<16|uint(intSum[byteOrder[1]])<<8|
+			uint(intSum[byteOrder[2]]), 4)
+	}
+	cryptBase64(&cryptString, uint(intSum[63]), 2)
+
+	return cryptString.String(), nil
+}
+
+// Repeat the given bytes until the given length is reached
+func repeatBytes(repBytes []byte, length int) []byte {
+	returnBytes := make([]byte, 0, length)
+	for len(returnBytes) < length {
+		remaining := length - len(returnBytes)
+		if remaining > len(repBytes) {
+			remaining = len(repBytes)
+		}
+		returnBytes = append(returnBytes, repBytes[:remaining]...)
+	}
+	return returnBytes
+}
+
+// Write the given number of characters of the crypt specific base64 encoding of the
+// given value
+func cryptBase64(cryptString *strings.Builder, value uint, numOfChars int) {
+	for i := 0; i < numOfChars; i++ {
+		cryptString.WriteByte(CryptSaltChars[value&0x3f])
+		value >>= 6
+	}
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// Attacker profiles (with their guesses per second) for the crack time estimation
+var attackerProfiles = []struct {
+	profileName      string
+	guessesPerSecond float64
+}{
+	{"online attack (throttled, 100 guesses per hour)", 100.0 / 3600},
+	{"online attack (unthrottled, 10 guesses per second)", 10},
+	{"offline attack (slow hash, 10 thousand guesses per second)", 1e4},
+	{"offline attack (fast hash, 10 billion guesses per second)", 1e10},
+}
+
+// Time units for the crack time formatting (in descending order)
+var crackTimeUnits = []struct {
+	unitName string
+	seconds  float64
+}{
+	{"year", 365.25 * 24 * 3600},
+	{"day", 24 * 3600},
+	{"hour", 3600},
+	{"minute", 60},
+	{"second", 1},
+}
+
+// Maximum share of all possible passwords that can be requested as distinct passwords
+const MaxKeyspaceShare float64 = 0.5
+
+// Relative tolerance for the keyspace size, which is derived from the (rounded) entropy
+const KeyspaceTolerance float64 = 1e-9
+
+// Crack times of more than a million years are not shown in detail
+const MaxCrackTimeYears float64 = 1e6
+
+// Calculate the theoretical entropy (in bits) of the passwords generated with the provided
+// parameters. If the password length is a range, the entropy of the shortest possible
+// password is returned. For random passwords, the characters allowed for the first and the last
+// character (-F, -J, -I) and the required character sets (-k, -B) are taken into account. For
+// random and pattern based passwords, the entropy that the repeat and the sequence limit (-R, -Q)
+// can take away is subtracted as well (see hasUncountedLimits for the other algorithms)
+func getEntropy(config *Config, charRange string, wordList []string) (float64, error) {
+	switch config.pwAlgo {
+	case AlgoRandom:
+		if len(charRange) == 0 {
+			err := fmt.Errorf("cannot calculate entropy of empty character range")
+			return 0, err
+		}
+		pwLength := config.minPassLen
+		if pwLength <= 0 {
+			pwLength = 1
+		}
+		pwEdges, _ := getEdgeRanges(config)
+		var entropy float64
+		var ok bool
+		if config.balancedClasses {
+			entropy, ok = calcBalancedEntropy(pwLength, getCharClasses(config), pwEdges.firstRange,
+				pwEdges.lastRange)
+		} else {
+			var requiredClasses []charClass
+			if config.requireAllModes {
+				requiredClasses = getCharClasses(config)
+			}
+			entropy, ok = calcRandomEntropy(pwLength, charRange, pwEdges.firstRange, pwEdges.lastRange,
+				requiredClasses)
+		}
+		if !ok {
+			err := fmt.Errorf("%w: no password with a length of %d can be generated", ErrNoEdgeChars,
+				pwLength)
+			return 0, err
+		}
+		entropy -= calcRandomLimitLoss(pwLength, charRange, pwEdges, config.maxRepeat, config.maxSequence)
+		return math.Max(entropy, 0), nil
+	case AlgoPassphrase:
+		if len(wordList) == 0 {
+			err := fmt.Errorf("cannot calculate entropy of empty word list")
+			return 0, err
+		}
+		entropy := calcEntropy(config.numOfWords, len(wordList))
+		if config.separatorSet != "" && config.numOfWords > 1 {
+			entropy += calcEntropy(config.numOfWords-1, len([]rune(cleanCharRange(config.separatorSet, ""))))
+		}
+		if config.injectChars {
+			for _, injectRange := range getInjectRanges(config) {
+				// Characters that passwords must not start or end with are only injected between words
+				numOfChoices := (config.numOfWords - 1) * len([]rune(injectRange))
+				numOfChoices += 2 * len([]rune(cleanCharRange(injectRange, config.trimUnsafeChars)))
+				entropy += calcEntropy(1, numOfChoices)
+			}
+		}
+		return entropy, nil
+	case AlgoKoremutake:
+		numOfSyllables := config.minPassLen
+		if numOfSyllables <= 0 {
+			numOfSyllables = 1
+		}
+		return calcEntropy(numOfSyllables, len(koremutakeSyllables)), nil
+	case AlgoPattern:
+		charRanges, err := parsePattern(config)
+		if err != nil {
+			return 0, err
+		}
+		var entropy float64
+		for i, curRange := range charRanges {
+			entropy += calcEntropy(1, len([]rune(curRange)))
+			if i > 0 {
+				entropy -= calcLimitLoss(charRanges[i-1], curRange, config.maxRepeat, config.maxSequence)
+			}
+		}
+		return math.Max(entropy, 0), nil
+	default:
+		err := fmt.Errorf("entropy calculation is not supported for %s passwords",
+			getAlgorithmName(config.pwAlgo))
+		return 0, err
+	}
+}
+
+// Check if the generated passwords are subject to a repeat or sequence limit that the entropy
+// calculation does not take into account. This is the case for passphrases and koremutake
+// passwords, whose words and syllables consist of several characters each
+func hasUncountedLimits(config *Config) bool {
+	if config.maxRepeat <= 0 && config.maxSequence <= 0 {
+		return false
+	}
+	return config.pwAlgo == AlgoPassphrase || config.pwAlgo == AlgoKoremutake
+}
+
+// Make sure that the passwords generated with the provided parameters have at least the
+// configured minimum entropy. For random and koremutake passwords the minimum password length
+// is raised accordingly (but never beyond the maximum password length). For all other
+// algorithms an error is returned if the entropy is too low
+func applyMinEntropy(config *Config, charRange string, wordList []string) error {
+	if config.minEntropy <= 0 {
+		return nil
+	}
+	if hasUncountedLimits(config) {
+		err := fmt.Errorf("a minimum entropy cannot be guaranteed for %s passwords with a repeat or "+
+			"sequence limit", getAlgorithmName(config.pwAlgo))
+		return err
+	}
+	poolSize := 0
+	switch config.pwAlgo {
+	case AlgoRandom:
+		poolSize = len([]rune(charRange))
+	case AlgoKoremutake:
+		poolSize = len(koremutakeSyllables)
+	}
+	if poolSize > 1 {
+		minLength := int(math.Ceil(config.minEntropy / math.Log2(float64(poolSize))))
+		maxLength := config.maxPassLen
+		if config.minPassLen > maxLength {
+			maxLength = config.minPassLen
+		}
+		if minLength > maxLength {
+			err := fmt.Errorf("a minimum entropy of %.2f bits requires a length of %d, but the maximum "+
+				"length is %d", config.minEntropy, minLength, maxLength)
+			return err
+		}
+		if minLength > config.minPassLen {
+			config.minPassLen = minLength
+		}
+	}
+
+	entropy, err := getEntropy(config, charRange, wordList)
+	if err != nil {
+		return err
+	}
+	// Balanced passwords, required character sets and restricted first or last characters
+	// reduce the entropy of random passwords of the same length
+	for config.pwAlgo == AlgoRandom && entropy < config.minEntropy && config.minPassLen < config.maxPassLen {
+		config.minPassLen++
+		entropy, err = getEntropy(config, charRange, wordList)
+		if err != nil {
+			return err
+		}
+	}
+	if entropy < config.minEntropy {
+		err := fmt.Errorf("the generated passwords would only provide %.2f bits of entropy, but a minimum "+
+			"of %.2f bits is required", entropy, config.minEntropy)
+		return err
+	}
+	return nil
+}
+
+// Make sure that the requested amount of distinct passwords does not exceed MaxKeyspaceShare
+// of all passwords that can be generated with the provided parameters. The size of the keyspace
+// is derived from the entropy, so no check is possible for pronounceable passwords
+func validateKeyspace(config *Config, charRange string, wordList []string) error {
+	if config.pwAlgo == AlgoPronounceable {
+		return nil
+	}
+	entropy, err := getEntropy(config, charRange, wordList)
+	if err != nil {
+		return err
+	}
+	keyspaceSize := math.Exp2(entropy)
+	if float64(config.numOfPass) > keyspaceSize*MaxKeyspaceShare*(1+KeyspaceTolerance) {
+		err := fmt.Errorf("%d distinct passwords requested, but only about %.0f different passwords can "+
+			"be generated (maximum share: %.0f%%)", config.numOfPass, keyspaceSize, MaxKeyspaceShare*100)
+		return err
+	}
+	return nil
+}
+
+// Calculate the entropy (in bits) of the given amount of elements (characters or words) that
+// have been randomly selected from a pool of the given size
+func calcEntropy(numOfElements, poolSize int) float64 {
+	return float64(numOfElements) * math.Log2(float64(poolSize))
+}
+
+// Calculate the entropy (in bits) of random passwords with the given length, whose first and
+// last character are taken from the given ranges (empty ranges allow every character) and that
+// contain at least one character of every given character class. The passwords that miss a
+// class are subtracted by inclusion-exclusion over all sets of missing classes. False is returned
+// if no such password exists
+func calcRandomEntropy(pwLength int, charRange, firstRange, lastRange string,
+	charClasses []charClass) (float64, bool) {
+	if firstRange == "" && lastRange == "" && len(charClasses) == 0 {
+		return calcEntropy(pwLength, len([]rune(charRange))), true
+	}
+	if firstRange == "" {
+		firstRange = charRange
+	}
+	if lastRange == "" {
+		lastRange = charRange
+	}
+
+	// The terms are calculated in the log domain and summed relative to the first term (no
+	// missing classes), which is the biggest one
+	var baseEntropy, countShare float64
+	for classMask := 0; classMask < 1<<len(charClasses); classMask++ {
+		var missingChars string
+		for i, curClass := range charClasses {
+			if classMask&(1<<i) != 0 {
+				missingChars = missingChars + curClass.charRange
+			}
+		}
+		var termEntropy float64
+		if pwLength == 1 {
+			edgeChars := countCharsLeft(filterCharRange(firstRange, lastRange), missingChars)
+			if edgeChars == 0 {
+				continue
+			}
+			termEntropy = calcEntropy(1, edgeChars)
+		} else {
+			firstChars := countCharsLeft(firstRange, missingChars)
+			lastChars := countCharsLeft(lastRange, missingChars)
+			innerChars := countCharsLeft(charRange, missingChars)
+			if firstChars == 0 || lastChars == 0 || (innerChars == 0 && pwLength > 2) {
+				continue
+			}
+			termEntropy = calcEntropy(1, firstChars) + calcEntropy(1, lastChars)
+			if pwLength > 2 {
+				termEntropy += calcEntropy(pwLength-2, innerChars)
+			}
+		}
+		if classMask == 0 {
+			baseEntropy = termEntropy
+		}
+		termShare := math.Exp2(termEntropy - baseEntropy)
+		if bits.OnesCount(uint(classMask))%2 == 1 {
+			termShare = -termShare
+		}
+		countShare += termShare
+	}
+	if countShare <= 0 {
+		return 0, false
+	}
+	return baseEntropy + math.Log2(countShare), true
+}
+
+// Calculate the entropy (in bits) that the repeat and the sequence limit can take away from
+// random passwords with the given length. All inner positions use the whole character range
+func calcRandomLimitLoss(pwLength int, charRange string, pwEdges edgeRanges,
+	maxRepeat, maxSequence int) float64 {
+	if maxRepeat <= 0 && maxSequence <= 0 {
+		return 0
+	}
+	var limitLoss float64
+	for charPos := 1; charPos < pwLength; charPos++ {
+		if charPos > 1 && charPos < pwLength-1 {
+			limitLoss += float64(pwLength-3) * calcLimitLoss(charRange, charRange, maxRepeat, maxSequence)
+			charPos = pwLength - 2
+			continue
+		}
+		limitLoss += calcLimitLoss(getPositionRange(charRange, pwEdges, charPos-1, pwLength),
+			getPositionRange(charRange, pwEdges, charPos, pwLength), maxRepeat, maxSequence)
+	}
+	return limitLoss
+}
+
+// Calculate the entropy (in bits) that the repeat and the sequence limit can take away from a
+// character of the current range that follows a character of the previous range. The repeat
+// limit excludes at most the previous character, the sequence limit excludes at most both of
+// its neighbours (or only one of them if sequences of 2 characters are allowed). Positive
+// infinity is returned if all characters of the current range can be excluded
+func calcLimitLoss(prevRange, curRange string, maxRepeat, maxSequence int) float64 {
+	numOfChars := len([]rune(curRange))
+	numOfExcluded := 0
+	if maxRepeat > 0 && strings.ContainsAny(prevRange, curRange) {
+		numOfExcluded++
+	}
+	if maxSequence > 0 {
+		maxExcluded := 1
+		if maxSequence == 1 {
+			maxExcluded = 2
+		}
+		seqExcluded := 0
+		for _, curChar := range curRange {
+			if seqExcluded < maxExcluded &&
+				(strings.ContainsRune(prevRange, curChar-1) || strings.ContainsRune(prevRange, curChar+1)) {
+				seqExcluded++
+			}
+		}
+		numOfExcluded += seqExcluded
+	}
+	if numOfExcluded >= numOfChars {
+		return math.Inf(1)
+	}
+	return math.Log2(float64(numOfChars)) - math.Log2(float64(numOfChars-numOfExcluded))
+}
+
+// Return the amount of characters of the given character range that are not part of the
+// given excluded characters
+func countCharsLeft(charRange, excludeChars string) int {
+	charsLeft := 0
+	for _, curChar := range charRange {
+		if !strings.ContainsRune(excludeChars, curChar) {
+			charsLeft++
+		}
+	}
+	return charsLeft
+}
+
+// Calculate the entropy (in bits) of passwords with the given length that use all given
+// character classes equally often and whose first and last character are taken from the given
+// ranges (empty ranges allow every character). The characters that cannot be split evenly are
+// counted for the classes that result in the lowest entropy (worst case). False is returned if
+// no such password exists
+func calcBalancedEntropy(pwLength int, charClasses []charClass,
+	firstRange, lastRange string) (float64, bool) {
+	if len(charClasses) == 0 {
+		return 0, false
+	}
+	numOfExtraChars := pwLength % len(charClasses)
+	classLengths := make([]int, len(charClasses))
+	minEntropy := math.Inf(1)
+	for extraMask := 0; extraMask < 1<<len(charClasses); extraMask++ {
+		if bits.OnesCount(uint(extraMask)) != numOfExtraChars {
+			continue
+		}
+		// The amount of ways to distribute the classes over the positions is the multinomial
+		// coefficient of the class lengths
+		entropy := calcLogFactorial(pwLength)
+		for i, curClass := range charClasses {
+			classLengths[i] = pwLength / len(charClasses)
+			if extraMask&(1<<i) != 0 {
+				classLengths[i]++
+			}
+			entropy += calcEntropy(classLengths[i], len([]rune(curClass.charRange))) -
+				calcLogFactorial(classLengths[i])
+		}
+		// Distributions without any valid edge characters are discarded by the generator
+		edgeShare := calcBalancedEdgeShare(pwLength, charClasses, classLengths, firstRange, lastRange)
+		if edgeShare <= 0 {
+			continue
+		}
+		entropy += math.Log2(edgeShare)
+		if entropy < minEntropy {
+			minEntropy = entropy
+		}
+	}
+	if math.IsInf(minEntropy, 0) {
+		return 0, false
+	}
+	if minEntropy < 0 {
+		return 0, true
+	}
+	return minEntropy, true
+}
+
+// Calculate the share of the balanced passwords with the given class lengths, whose first and
+// last character are part of the given ranges (empty ranges allow every character)
+func calcBalancedEdgeShare(pwLength int, charClasses []charClass, classLengths []int,
+	firstRange, lastRange string) float64 {
+	if firstRange == "" && lastRange == "" {
+		return 1
+	}
+	rangeShare := func(classRange string, edgeRanges ...string) float64 {
+		edgeChars := classRange
+		for _, edgeRange := range edgeRanges {
+			if edgeRange != "" {
+				edgeChars = filterCharRange(edgeChars, edgeRange)
+			}
+		}
+		return float64(len([]rune(edgeChars))) / float64(len([]rune(classRange)))
+	}
+	var edgeShare float64
+	for i, firstClass := range charClasses {
+		if pwLength == 1 {
+			edgeShare += float64(classLengths[i]) * rangeShare(firstClass.charRange, firstRange, lastRange)
+			continue
+		}
+		for j, lastClass := range charClasses {
+			lastLength := classLengths[j]
+			if i == j {
+				lastLength--
+			}
+			edgeShare += float64(classLengths[i]*lastLength) / float64(pwLength*(pwLength-1)) *
+				rangeShare(firstClass.charRange, firstRange) * rangeShare(lastClass.charRange, lastRange)
+		}
+	}
+	return edgeShare
+}
+
+// Calculate the binary logarithm of the factorial of the given number
+func calcLogFactorial(num int) float64 {
+	logFactorial, _ := math.Lgamma(float64(num + 1))
+	return logFactorial / math.Ln2
+}
+
+// Estimate the average time (in seconds) an attacker with the given amount of guesses per
+// second needs to crack a password with the given entropy. On average, half of all possible
+// passwords need to be guessed
+func getCrackTime(entropy float64, guessesPerSecond float64) float64 {
+	return math.Exp2(entropy-1) / guessesPerSecond
+}
+
+// Format the given crack time (in seconds) as human-readable string (i. e. "about 3 years")
+func formatCrackTime(crackTime float64) string {
+	if crackTime < 1 {
+		return "less than a second"
+	}
+	if crackTime >= MaxCrackTimeYears*crackTimeUnits[0].seconds {
+		return "more than a million years"
+	}
+	for _, curUnit := range crackTimeUnits {
+		if crackTime < curUnit.seconds {
+			continue
+		}
+		numOfUnits := math.Round(crackTime / curUnit.seconds)
+		if numOfUnits == 1 {
+			return "about 1 " + curUnit.unitName
+		}
+		return fmt.Sprintf("about %.0f %ss", numOfUnits, curUnit.unitName)
+	}
+	return "less than a second"
+}
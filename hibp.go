@@ -10,14 +10,25 @@ import (
 	"time"
 )
 
+// Base URL of the HIBP range API
+var hibpAPIURL = "https://api.pwnedpasswords.com/range/"
+
+// HTTP client used for the HIBP API requests
+var hibpHTTPClient = &http.Client{Timeout: time.Second * 2}
+
+// Maximum amount of passwords that are checked against the HIBP database for every password
+// to be generated. If all of them were found in a leak, the last one is shown with a warning
+const MaxHibpAttempts = 10
+
+// Check the HIBP database if the given password was found in a leak before. Only the first
+// 5 characters of the password's SHA-1 hash are sent to the API (k-anonymity)
 func checkHibp(p string) (bool, error) {
 	shaSum := fmt.Sprintf("%x", sha1.Sum([]byte(p)))
 	firstPart := shaSum[0:5]
 	secondPart := shaSum[5:]
 	isPwned := false
 
-	httpClient := &http.Client{Timeout: time.Second * 2}
-	httpRes, err := httpClient.Get("https://api.pwnedpasswords.com/range/" + firstPart)
+	httpRes, err := hibpHTTPClient.Get(hibpAPIURL + firstPart)
 	if err != nil {
 		return false, err
 	}
@@ -28,6 +39,11 @@ func checkHibp(p string) (bool, error) {
 		}
 	}()
 
+	if httpRes.StatusCode != http.StatusOK {
+		err := fmt.Errorf("HIBP API returned unexpected HTTP status: %s", httpRes.Status)
+		return false, err
+	}
+
 	scanObj := bufio.NewScanner(httpRes.Body)
 	for scanObj.Scan() {
 		scanLine := strings.SplitN(scanObj.Text(), ":", 2)
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+)
+
+// Rows of the (unshifted) keys of the supported keyboard layouts. Every row is shifted by half
+// a key to the right compared to the row above, so that i. e. q is adjacent to 1, 2, w and a
+var keyboardLayouts = map[string][]string{
+	"qwerty": {"1234567890-=", "qwertyuiop[]", "asdfghjkl;'", "zxcvbnm,./"},
+	"qwertz": {"1234567890ß", "qwertzuiopü", "asdfghjklöä", "yxcvbnm,.-"},
+	"azerty": {"1234567890", "azertyuiop", "qsdfghjklm", "wxcvbn,;:!"},
+}
+
+// Position of a key on a keyboard layout
+type keyPosition struct {
+	row    int
+	column int
+}
+
+// Check if the given password contains a keyboard walk (i. e. qwer, asdf or 1qaz) that is
+// longer than maxWalk characters on any of the supported keyboard layouts. A keyboard walk
+// is a run of characters where every character is horizontally or diagonally adjacent to the
+// previous one. A maxWalk of 0 disables the check
+func hasKeyboardWalk(pwString string, maxWalk int) bool {
+	if maxWalk <= 0 {
+		return false
+	}
+	pwRunes := []rune(strings.ToLower(pwString))
+	for _, layoutRows := range keyboardLayouts {
+		keyPositions := getKeyPositions(layoutRows)
+		walkLength := 1
+		for i := 1; i < len(pwRunes); i++ {
+			prevPos, prevOk := keyPositions[pwRunes[i-1]]
+			curPos, curOk := keyPositions[pwRunes[i]]
+			if !prevOk || !curOk || !isAdjacentKey(prevPos, curPos) {
+				walkLength = 1
+				continue
+			}
+			walkLength++
+			if walkLength > maxWalk {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Provide the positions of all keys of the given keyboard layout rows
+func getKeyPositions(layoutRows []string) map[rune]keyPosition {
+	keyPositions := make(map[rune]keyPosition)
+	for rowNum, curRow := range layoutRows {
+		for columnNum, curKey := range []rune(curRow) {
+			keyPositions[curKey] = keyPosition{row: rowNum, column: columnNum}
+		}
+	}
+	return keyPositions
+}
+
+// Check if the two given key positions are horizontally or diagonally adjacent
+func isAdjacentKey(firstPos, secondPos keyPosition) bool {
+	rowDiff := secondPos.row - firstPos.row
+	columnDiff := secondPos.column - firstPos.column
+	switch rowDiff {
+	case 0:
+		return columnDiff == 1 || columnDiff == -1
+	case 1:
+		return columnDiff == 0 || columnDiff == -1
+	case -1:
+		return columnDiff == 0 || columnDiff == 1
+	default:
+		return false
+	}
+}
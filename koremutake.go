@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Koremutake syllables (the index of the syllable is its 7 bit value)
+var koremutakeSyllables = []string{
+	"ba", "be", "bi", "bo", "bu", "by", "da", "de", "di", "do", "du", "dy", "fa", "fe", "fi", "fo",
+	"fu", "fy", "ga", "ge", "gi", "go", "gu", "gy", "ha", "he", "hi", "ho", "hu", "hy", "ja", "je",
+	"ji", "jo", "ju", "jy", "ka", "ke", "ki", "ko", "ku", "ky", "la", "le", "li", "lo", "lu", "ly",
+	"ma", "me", "mi", "mo", "mu", "my", "na", "ne", "ni", "no", "nu", "ny", "pa", "pe", "pi", "po",
+	"pu", "py", "ra", "re", "ri", "ro", "ru", "ry", "sa", "se", "si", "so", "su", "sy", "ta", "te",
+	"ti", "to", "tu", "ty", "va", "ve", "vi", "vo", "vu", "vy", "bra", "bre", "bri", "bro", "bru", "bry",
+	"dra", "dre", "dri", "dro", "dru", "dry", "fra", "fre", "fri", "fro", "fru", "fry", "gra", "gre", "gri", "gro",
+	"gru", "gry", "pra", "pre", "pri", "pro", "pru", "pry", "sta", "ste", "sti", "sto", "stu", "sty", "tra", "tre",
+}
+
+// Generate a koremutake password by encoding a random number that consists of the given
+// amount of syllables (7 bits per syllable)
+func getKoremutakePassword(numOfSyllables int) (string, error) {
+	if numOfSyllables <= 0 {
+		err := fmt.Errorf("provided numOfSyllables value is <= 0: %v", numOfSyllables)
+		return "", err
+	}
+	if randReader == nil {
+		err := fmt.Errorf("no source of randomness provided")
+		return "", err
+	}
+	maxNum := new(big.Int).Lsh(big.NewInt(1), uint(7*numOfSyllables))
+	randNum, err := rand.Int(randReader, maxNum)
+	if err != nil {
+		err = fmt.Errorf("random number generation failed: %w", err)
+		return "", err
+	}
+
+	// Leading zero values are encoded as "ba" so that the password has the requested length
+	pwString := koremutakeEncode(randNum)
+	for len(koremutakeSplit(pwString)) < numOfSyllables {
+		pwString = koremutakeSyllables[0] + pwString
+	}
+	return pwString, nil
+}
+
+// Encode the given (non-negative) number as koremutake string
+func koremutakeEncode(num *big.Int) string {
+	if num.Sign() <= 0 {
+		return koremutakeSyllables[0]
+	}
+	var pwSyllables []string
+	curNum := new(big.Int).Set(num)
+	syllableNum := new(big.Int)
+	numOfSyllables := big.NewInt(int64(len(koremutakeSyllables)))
+	for curNum.Sign() > 0 {
+		curNum.DivMod(curNum, numOfSyllables, syllableNum)
+		pwSyllables = append([]string{koremutakeSyllables[syllableNum.Int64()]}, pwSyllables...)
+	}
+	return strings.Join(pwSyllables, "")
+}
+
+// Split the given koremutake string into its syllables (every syllable ends with a vowel)
+func koremutakeSplit(koremutakeString string) []string {
+	var syllables []string
+	for koremutakeString != "" {
+		syllableEnd := strings.IndexAny(koremutakeString, "aeiouy") + 1
+		if syllableEnd == 0 {
+			syllableEnd = len(koremutakeString)
+		}
+		syllables = append(syllables, koremutakeString[:syllableEnd])
+		koremutakeString = koremutakeString[syllableEnd:]
+	}
+	return syllables
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Default settings for passphrase generation
+const DefaultNumOfWords int = 6
+const DefaultWordSeparator string = "-"
+
+// Minimum amount of distinct words a word list needs to provide
+const MinWordListSize int = 1024
+
+// Error returned when a word list provides less than MinWordListSize words
+var ErrWordListTooSmall = errors.New("word list is too small")
+
+// Generate a passphrase from randomly selected words of the given word list
+func getPassphrase(config *Config, wordList []string) (string, error) {
+	if config.numOfWords <= 0 {
+		err := fmt.Errorf("provided numOfWords value is <= 0: %v", config.numOfWords)
+		return "", err
+	}
+	if len(wordList) == 0 {
+		err := fmt.Errorf("provided word list is empty")
+		return "", err
+	}
+
+	passPhrase := make([]string, config.numOfWords)
+	for i := 0; i < config.numOfWords; i++ {
+		randNum, err := getRandNum(len(wordList))
+		if err != nil {
+			return "", err
+		}
+		passPhrase[i] = capitalizeWord(config, wordList[randNum])
+	}
+	if config.injectChars {
+		for _, injectRange := range getInjectRanges(config) {
+			if err := injectRandChar(passPhrase, injectRange, config.trimUnsafeChars); err != nil {
+				return "", err
+			}
+		}
+	}
+	if config.separatorSet != "" {
+		return joinRandSeparators(passPhrase, config.separatorSet)
+	}
+	return strings.Join(passPhrase, config.wordSeparator), nil
+}
+
+// Join the given passphrase words with separators that are randomly selected from the
+// given set of characters for every word boundary
+func joinRandSeparators(passPhrase []string, separatorSet string) (string, error) {
+	separatorRange := cleanCharRange(separatorSet, "")
+	var joinedPhrase strings.Builder
+	for i, curWord := range passPhrase {
+		if i > 0 {
+			randSeparator, err := getRandChar(&separatorRange, 1)
+			if err != nil {
+				return "", err
+			}
+			joinedPhrase.WriteString(randSeparator)
+		}
+		joinedPhrase.WriteString(curWord)
+	}
+	return joinedPhrase.String(), nil
+}
+
+// Provide the character ranges of which one random character each is injected into a
+// passphrase. A numeric character is injected if numbers are enabled and a special
+// character if special characters are enabled
+func getInjectRanges(config *Config) []string {
+	var injectRanges []string
+	if config.useNumber {
+		numberConfig := getFilterConfig(config)
+		numberConfig.useNumber = true
+		injectRanges = append(injectRanges, getCharRange(&numberConfig))
+	}
+	if config.useSpecial {
+		specialConfig := getFilterConfig(config)
+		specialConfig.useSpecial = true
+		injectRanges = append(injectRanges, getCharRange(&specialConfig))
+	}
+	return injectRanges
+}
+
+// Inject a random character of the given character range at a random word boundary of the
+// given passphrase words (in front of one of the words or behind the last word), so that
+// the character never ends up inside a word. The given unsafe characters are only injected
+// between two words, as passphrases must not start or end with them
+func injectRandChar(passPhrase []string, injectRange string, unsafeChars string) error {
+	if len(passPhrase) == 1 && cleanCharRange(injectRange, unsafeChars) == "" {
+		err := fmt.Errorf("%w: passphrases must not start or end with any of the characters %q",
+			ErrNoTrimSafeChars, injectRange)
+		return err
+	}
+	var injectChar string
+	var wordBoundary int
+	for {
+		var err error
+		injectChar, err = getRandChar(&injectRange, 1)
+		if err != nil {
+			return err
+		}
+		wordBoundary, err = getRandNum(len(passPhrase) + 1)
+		if err != nil {
+			return err
+		}
+		if (wordBoundary > 0 && wordBoundary < len(passPhrase)) || !strings.ContainsAny(injectChar, unsafeChars) {
+			break
+		}
+	}
+	if wordBoundary == len(passPhrase) {
+		passPhrase[len(passPhrase)-1] = passPhrase[len(passPhrase)-1] + injectChar
+		return nil
+	}
+	passPhrase[wordBoundary] = injectChar + passPhrase[wordBoundary]
+	return nil
+}
+
+// Change the case of the given word based on the provided parameters. If lower and upper
+// case characters are enabled, the first character of the word is capitalized. If only
+// upper case characters are enabled, the whole word is capitalized
+func capitalizeWord(config *Config, word string) string {
+	word = strings.ToLower(word)
+	if !config.useUpperCase {
+		return word
+	}
+	if !config.useLowerCase {
+		return strings.ToUpper(word)
+	}
+	wordRunes := []rune(word)
+	wordRunes[0] = unicode.ToUpper(wordRunes[0])
+	return string(wordRunes)
+}
+
+// Read the word list for passphrase generation from the given file (one word per line).
+// Blank lines and lines starting with a '#' are ignored and duplicate words are only used
+// once, so that no word is more likely to be selected than the others
+func readWordList(fileName string) ([]string, error) {
+	if fileName == "" {
+		err := fmt.Errorf("no word list file provided")
+		return nil, err
+	}
+	fileHandle, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = fileHandle.Close()
+	}()
+
+	var wordList []string
+	seenWords := make(map[string]bool)
+	scanObj := bufio.NewScanner(fileHandle)
+	for scanObj.Scan() {
+		curWord := strings.ToLower(strings.TrimSpace(scanObj.Text()))
+		if curWord == "" || strings.HasPrefix(curWord, "#") || seenWords[curWord] {
+			continue
+		}
+		seenWords[curWord] = true
+		wordList = append(wordList, curWord)
+	}
+	if err := scanObj.Err(); err != nil {
+		return nil, err
+	}
+	if len(wordList) < MinWordListSize {
+		err := fmt.Errorf("%w: word list file %q provides %d words, but at least %d are required",
+			ErrWordListTooSmall, fileName, len(wordList), MinWordListSize)
+		return nil, err
+	}
+
+	return wordList, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Parse the given password pattern and return the character range for each position of
+// the password. Supported tokens are ?l (lower case), ?u (upper case), ?d (numeric),
+// ?s (special), ?a (any of the previous) and ?? (literal question mark). All other
+// characters are used literally. The characters that passwords must not start or end with are
+// removed from the first and the last character range
+func parsePattern(config *Config) ([]string, error) {
+	patternRunes := []rune(config.pwPattern)
+	if len(patternRunes) == 0 {
+		err := fmt.Errorf("provided password pattern is empty")
+		return nil, err
+	}
+
+	var charRanges []string
+	for i := 0; i < len(patternRunes); i++ {
+		if patternRunes[i] != '?' {
+			charRanges = append(charRanges, string(patternRunes[i]))
+			continue
+		}
+		if i+1 >= len(patternRunes) {
+			err := fmt.Errorf("incomplete pattern token at offset %d", i)
+			return nil, err
+		}
+		tokenConfig := getFilterConfig(config)
+		switch patternRunes[i+1] {
+		case 'l':
+			tokenConfig.useLowerCase = true
+		case 'u':
+			tokenConfig.useUpperCase = true
+		case 'd':
+			tokenConfig.useNumber = true
+		case 's':
+			tokenConfig.useSpecial = true
+		case 'a':
+			tokenConfig.useLowerCase = true
+			tokenConfig.useUpperCase = true
+			tokenConfig.useNumber = true
+			tokenConfig.useSpecial = true
+		case '?':
+			charRanges = append(charRanges, "?")
+			i++
+			continue
+		default:
+			err := fmt.Errorf("unknown pattern token %q at offset %d", "?"+string(patternRunes[i+1]), i)
+			return nil, err
+		}
+		charRange := getCharRange(&tokenConfig)
+		if charRange == "" {
+			err := fmt.Errorf("no characters left for pattern token %q at offset %d",
+				"?"+string(patternRunes[i+1]), i)
+			return nil, err
+		}
+		charRanges = append(charRanges, charRange)
+		i++
+	}
+	for _, charPos := range []int{0, len(charRanges) - 1} {
+		safeRange := cleanCharRange(charRanges[charPos], config.trimUnsafeChars)
+		if safeRange == "" {
+			err := fmt.Errorf("%w: passwords must not start or end with any of the characters %q of "+
+				"the pattern", ErrNoTrimSafeChars, charRanges[charPos])
+			return nil, err
+		}
+		charRanges[charPos] = safeRange
+	}
+	return charRanges, nil
+}
+
+// Generate a password that matches the given character ranges (one per position)
+func getPatternPassword(charRanges []string) (string, error) {
+	var pwString string
+	for i := range charRanges {
+		randChar, err := getRandChar(&charRanges[i], 1)
+		if err != nil {
+			return "", err
+		}
+		pwString = pwString + randChar
+	}
+	return pwString, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Characters used to build pronounceable syllables
+const PronConsonants string = "bcdfghjklmnprstvwxz"
+const PronVowels string = "aeiouy"
+
+// Syllable templates (C = consonant, V = vowel) used for pronounceable passwords
+var pronSyllableTemplates = []string{"CV", "VC", "CVC"}
+
+// Maximum amount of tokens of a syllable template
+const MaxSyllableTemplateLength int = 8
+
+// Set of consonants and vowels a syllable can be constructed from
+type syllableSet struct {
+	consonants string
+	vowels     string
+}
+
+// Generate the syllables of a pronounceable password (similar to FIPS-181) with the given
+// length. If numbers, special characters or custom characters are enabled, they are randomly
+// placed between two syllables (or at the end) and returned as their own elements. The last
+// syllable is shortened if required, so that the joined syllables have the given length
+func getPronounceableSyllables(config *Config, pwLength int) ([]string, error) {
+	if pwLength <= 0 {
+		err := fmt.Errorf("provided pwLength value is <= 0: %v", pwLength)
+		return nil, err
+	}
+	syllableTemplates := getSyllableTemplates(config)
+	if err := validateSyllableTemplates(syllableTemplates); err != nil {
+		return nil, err
+	}
+	syllableSets := getSyllableSets(config)
+	if len(syllableSets) == 0 {
+		err := fmt.Errorf("%w: the character set does not provide the consonants or vowels of the syllable "+
+			"templates %q", ErrNoSyllableChars, syllableTemplates)
+		return nil, err
+	}
+	extraConfig := *config
+	extraConfig.useLowerCase = false
+	extraConfig.useUpperCase = false
+	extraChars := ""
+	if extraConfig.useNumber || extraConfig.useSpecial || extraConfig.customChars != "" {
+		extraChars = getCharRange(&extraConfig)
+	}
+	// Extra characters at the end of the password must not be one of the trim-unsafe characters
+	lastExtraChars := cleanCharRange(extraChars, config.trimUnsafeChars)
+
+	var pwSyllables []string
+	curLength := 0
+	addedSyllable := false
+	for curLength < pwLength {
+		curSyllable := ""
+		curExtraChars := extraChars
+		if curLength == pwLength-1 {
+			curExtraChars = lastExtraChars
+		}
+		if addedSyllable && curExtraChars != "" {
+			addExtra, err := getRandNum(2)
+			if err != nil {
+				return nil, err
+			}
+			if addExtra == 1 {
+				curSyllable, err = getRandChar(&curExtraChars, 1)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		addedSyllable = curSyllable == ""
+		if addedSyllable {
+			var err error
+			curSyllable, err = getRandSyllable(syllableSets, syllableTemplates)
+			if err != nil {
+				return nil, err
+			}
+		}
+		syllableRunes := []rune(curSyllable)
+		if curLength+len(syllableRunes) > pwLength {
+			syllableRunes = syllableRunes[:pwLength-curLength]
+		}
+		pwSyllables = append(pwSyllables, string(syllableRunes))
+		curLength += len(syllableRunes)
+	}
+
+	return pwSyllables, nil
+}
+
+// Generate a random syllable from one of the given syllable sets based on one of the given
+// syllable templates
+func getRandSyllable(syllableSets []syllableSet, syllableTemplates []string) (string, error) {
+	setNum, err := getRandNum(len(syllableSets))
+	if err != nil {
+		return "", err
+	}
+	curSet := syllableSets[setNum]
+	templateNum, err := getRandNum(len(syllableTemplates))
+	if err != nil {
+		return "", err
+	}
+
+	var syllable string
+	for _, curToken := range syllableTemplates[templateNum] {
+		charRange := curSet.vowels
+		if curToken == 'C' {
+			charRange = curSet.consonants
+		}
+		randChar, err := getRandChar(&charRange, 1)
+		if err != nil {
+			return "", err
+		}
+		syllable = syllable + randChar
+	}
+	return syllable, nil
+}
+
+// Parse the given comma-separated list of syllable templates (i. e. "CV,CVC"). The tokens
+// are not case-sensitive and empty templates are ignored
+func parseSyllableTemplates(templateString string) []string {
+	var syllableTemplates []string
+	for _, curTemplate := range strings.Split(templateString, ",") {
+		curTemplate = strings.ToUpper(strings.TrimSpace(curTemplate))
+		if curTemplate != "" {
+			syllableTemplates = append(syllableTemplates, curTemplate)
+		}
+	}
+	return syllableTemplates
+}
+
+// Provide the configured syllable templates or the default ones if none are configured
+func getSyllableTemplates(config *Config) []string {
+	if len(config.syllableTemplates) == 0 {
+		return pronSyllableTemplates
+	}
+	return config.syllableTemplates
+}
+
+// Make sure that the given syllable templates only consist of C (consonant) and V (vowel)
+// tokens and are not longer than MaxSyllableTemplateLength
+func validateSyllableTemplates(syllableTemplates []string) error {
+	for _, curTemplate := range syllableTemplates {
+		if curTemplate == "" || len(curTemplate) > MaxSyllableTemplateLength {
+			err := fmt.Errorf("syllable template %q needs to have 1 to %d tokens", curTemplate,
+				MaxSyllableTemplateLength)
+			return err
+		}
+		if i := strings.IndexFunc(curTemplate, func(curToken rune) bool {
+			return curToken != 'C' && curToken != 'V'
+		}); i >= 0 {
+			err := fmt.Errorf("unknown token %q in syllable template %q (valid tokens: C, V)",
+				curTemplate[i], curTemplate)
+			return err
+		}
+	}
+	return nil
+}
+
+// Provide the lower case and/or upper case syllable sets based on the provided parameters.
+// Consonants and vowels are only required if any of the syllable templates uses them
+func getSyllableSets(config *Config) []syllableSet {
+	var syllableSets []syllableSet
+	syllableTokens := strings.Join(getSyllableTemplates(config), "")
+	isUsable := func(curSet syllableSet) bool {
+		return (curSet.consonants != "" || !strings.ContainsRune(syllableTokens, 'C')) &&
+			(curSet.vowels != "" || !strings.ContainsRune(syllableTokens, 'V'))
+	}
+	letterConfig := *config
+	letterConfig.useNumber = false
+	letterConfig.useSpecial = false
+	letterConfig.customChars = ""
+
+	if config.useLowerCase {
+		letterConfig.useUpperCase = false
+		letterConfig.useLowerCase = true
+		curSet := newSyllableSet(getCharRange(&letterConfig), PronConsonants, PronVowels)
+		if isUsable(curSet) {
+			syllableSets = append(syllableSets, curSet)
+		}
+	}
+	if config.useUpperCase {
+		letterConfig.useUpperCase = true
+		letterConfig.useLowerCase = false
+		curSet := newSyllableSet(getCharRange(&letterConfig), strings.ToUpper(PronConsonants),
+			strings.ToUpper(PronVowels))
+		if isUsable(curSet) {
+			syllableSets = append(syllableSets, curSet)
+		}
+	}
+
+	return syllableSets
+}
+
+// Create a new syllable set containing only the consonants and vowels that are part of
+// the given character range
+func newSyllableSet(charRange, consonants, vowels string) syllableSet {
+	filterChars := func(chars string) string {
+		var filteredChars string
+		for _, curChar := range chars {
+			if strings.ContainsRune(charRange, curChar) {
+				filteredChars = filteredChars + string(curChar)
+			}
+		}
+		return filteredChars
+	}
+	return syllableSet{consonants: filterChars(consonants), vowels: filterChars(vowels)}
+}
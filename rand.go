@@ -3,16 +3,28 @@ package main
 import (
 	"crypto/rand"
 	"fmt"
+	"io"
 	"math/big"
+	"strings"
 )
 
+// Maximum amount of passwords generated to find one that meets all requirements
+const MaxGenerationAttempts int = 10000
+
+// Source of randomness for all random number generation. Defaults to crypto/rand
+var randReader io.Reader = rand.Reader
+
 // Generate random characters based on given character range
-// and password length
+// and password length. The password length is counted in characters (runes)
 func getRandChar(charRange *string, pwLength int) (string, error) {
 	if pwLength <= 0 {
 		err := fmt.Errorf("provided pwLength value is <= 0: %v", pwLength)
 		return "", err
 	}
+	runeSlice := []rune(*charRange)
+	if len(runeSlice) != len(*charRange) {
+		return getRandRunes(runeSlice, pwLength)
+	}
 	availCharsLength := len(*charRange)
 	charSlice := []byte(*charRange)
 	returnString := make([]byte, pwLength)
@@ -26,6 +38,193 @@ func getRandChar(charRange *string, pwLength int) (string, error) {
 	return string(returnString), nil
 }
 
+// Generate random characters based on given character range and password length,
+// making sure that no character is repeated more than maxRepeat times in a row and that
+// the password contains no character sequences (i. e. abc or 321) longer than maxSequence.
+// A limit of 0 disables the according check. The first and the last character are only
+// drawn from the characters of the given edge ranges, so no password has to be discarded
+// because of them
+func getRandCharLimited(charRange *string, pwLength int, maxRepeat int, maxSequence int,
+	pwEdges edgeRanges) (string, error) {
+	if maxRepeat <= 0 && maxSequence <= 0 && pwEdges == (edgeRanges{}) {
+		return getRandChar(charRange, pwLength)
+	}
+	if pwLength <= 0 {
+		err := fmt.Errorf("provided pwLength value is <= 0: %v", pwLength)
+		return "", err
+	}
+	cleanRange := cleanCharRange(*charRange, "")
+	returnRunes := make([]rune, 0, pwLength)
+	candidates := make([]rune, 0, len(cleanRange))
+	for len(returnRunes) < pwLength {
+		candidates = candidates[:0]
+		for _, curChar := range getPositionRange(cleanRange, pwEdges, len(returnRunes), pwLength) {
+			if !exceedsCharLimits(append(returnRunes, curChar), maxRepeat, maxSequence) {
+				candidates = append(candidates, curChar)
+			}
+		}
+		if len(candidates) == 0 {
+			err := fmt.Errorf("character range %q cannot satisfy the repeat limit of %d and the sequence "+
+				"limit of %d at position %d", *charRange, maxRepeat, maxSequence, len(returnRunes)+1)
+			return "", err
+		}
+		randNum, err := getRandNum(len(candidates))
+		if err != nil {
+			return "", err
+		}
+		returnRunes = append(returnRunes, candidates[randNum])
+	}
+	return string(returnRunes), nil
+}
+
+// Generate random characters like getRandCharLimited, but make sure that the password contains
+// at least one character of every given character class. Passwords that miss a class are
+// discarded, so that all valid passwords are equally likely
+func getRandCharAllClasses(charRange *string, pwLength int, maxRepeat int, maxSequence int,
+	charClasses []charClass, pwEdges edgeRanges) (string, error) {
+	if pwLength < len(charClasses) {
+		err := fmt.Errorf("provided pwLength value is too small for %d character classes: %v",
+			len(charClasses), pwLength)
+		return "", err
+	}
+	for i := 0; i < MaxGenerationAttempts; i++ {
+		pwString, err := getRandCharLimited(charRange, pwLength, maxRepeat, maxSequence, pwEdges)
+		if err != nil {
+			return "", err
+		}
+		if hasAllCharClasses(pwString, charClasses) {
+			return pwString, nil
+		}
+	}
+	err := fmt.Errorf("unable to generate a password with all %d character classes after %d attempts",
+		len(charClasses), MaxGenerationAttempts)
+	return "", err
+}
+
+// Generate random characters that use all given character classes equally often. The
+// characters that cannot be split evenly are assigned to randomly selected classes. The
+// characters of the edge positions are drawn first, so that they match the given edge ranges.
+// The classes of the other positions are shuffled afterwards and passwords that exceed the
+// repeat or sequence limit are discarded
+func getRandCharBalanced(pwLength int, maxRepeat int, maxSequence int, charClasses []charClass,
+	pwEdges edgeRanges) (string, error) {
+	if len(charClasses) == 0 || pwLength < len(charClasses) {
+		err := fmt.Errorf("provided pwLength value is too small for %d character classes: %v",
+			len(charClasses), pwLength)
+		return "", err
+	}
+	var classRange string
+	for _, curClass := range charClasses {
+		classRange = classRange + curClass.charRange
+	}
+	numOfEdgeFailures := 0
+	for i := 0; i < MaxGenerationAttempts; i++ {
+		classOrder := make([]rune, len(charClasses))
+		for j := range classOrder {
+			classOrder[j] = rune(j)
+		}
+		if err := shuffleRunes(classOrder); err != nil {
+			return "", err
+		}
+		pwClasses := make([]rune, 0, pwLength)
+		for j, classNum := range classOrder {
+			numOfChars := pwLength / len(charClasses)
+			if j < pwLength%len(charClasses) {
+				numOfChars++
+			}
+			for k := 0; k < numOfChars; k++ {
+				pwClasses = append(pwClasses, classNum)
+			}
+		}
+
+		pwRunes := make([]rune, pwLength)
+		pwClasses, ok, err := drawBalancedEdgeChars(pwRunes, pwClasses, charClasses, classRange, pwEdges)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			numOfEdgeFailures++
+			continue
+		}
+		if err := shuffleRunes(pwClasses); err != nil {
+			return "", err
+		}
+		for j, classNum := range pwClasses {
+			classChar, err := getRandChar(&charClasses[classNum].charRange, 1)
+			if err != nil {
+				return "", err
+			}
+			pwRunes[j+1] = []rune(classChar)[0]
+		}
+		if !hasCharLimitViolation(pwRunes, maxRepeat, maxSequence) {
+			return string(pwRunes), nil
+		}
+	}
+	if numOfEdgeFailures == MaxGenerationAttempts {
+		err := fmt.Errorf("unable to generate a balanced password that starts and ends with the allowed "+
+			"characters after %d attempts", MaxGenerationAttempts)
+		return "", err
+	}
+	err := fmt.Errorf("unable to generate a balanced password that satisfies the repeat limit of %d and "+
+		"the sequence limit of %d after %d attempts", maxRepeat, maxSequence, MaxGenerationAttempts)
+	return "", err
+}
+
+// Draw the characters of the edge positions of a balanced password from the given classes (one
+// per character of the password). A class is selected at random and a character of it is redrawn
+// until it matches the edge range of the position. The remaining classes are returned, or false
+// if none of the classes can provide a character for one of the edge positions
+func drawBalancedEdgeChars(pwRunes []rune, pwClasses []rune, charClasses []charClass, classRange string,
+	pwEdges edgeRanges) ([]rune, bool, error) {
+	edgePositions := []int{0, len(pwRunes) - 1}
+	if len(pwRunes) == 1 {
+		edgePositions = edgePositions[:1]
+	}
+	for _, charPos := range edgePositions {
+		edgeRange := getPositionRange(classRange, pwEdges, charPos, len(pwRunes))
+		matchingClass := false
+		for _, classNum := range pwClasses {
+			if strings.ContainsAny(charClasses[classNum].charRange, edgeRange) {
+				matchingClass = true
+				break
+			}
+		}
+		if !matchingClass {
+			return pwClasses, false, nil
+		}
+		for {
+			classIndex, err := getRandNum(len(pwClasses))
+			if err != nil {
+				return nil, false, err
+			}
+			edgeChar, err := getRandChar(&charClasses[pwClasses[classIndex]].charRange, 1)
+			if err != nil {
+				return nil, false, err
+			}
+			if strings.Contains(edgeRange, edgeChar) {
+				pwRunes[charPos] = []rune(edgeChar)[0]
+				pwClasses = append(pwClasses[:classIndex], pwClasses[classIndex+1:]...)
+				break
+			}
+		}
+	}
+	return pwClasses, true, nil
+}
+
+// Generate random characters based on given multi-byte character range
+// and password length
+func getRandRunes(runeSlice []rune, pwLength int) (string, error) {
+	returnRunes := make([]rune, pwLength)
+	for i := 0; i < pwLength; i++ {
+		randNum, err := getRandNum(len(runeSlice))
+		if err != nil {
+			return "", err
+		}
+		returnRunes[i] = runeSlice[randNum]
+	}
+	return string(returnRunes), nil
+}
+
 // Generate a random number with given maximum value
 func getRandNum(maxNum int) (int, error) {
 	if maxNum <= 0 {
@@ -37,8 +236,13 @@ func getRandNum(maxNum int) (int, error) {
 		err := fmt.Errorf("big.NewInt() generation returned negative value: %v", maxNumBigInt)
 		return 0, err
 	}
-	randNum64, err := rand.Int(rand.Reader, maxNumBigInt)
+	if randReader == nil {
+		err := fmt.Errorf("no source of randomness provided")
+		return 0, err
+	}
+	randNum64, err := rand.Int(randReader, maxNumBigInt)
 	if err != nil {
+		err = fmt.Errorf("random number generation failed: %w", err)
 		return 0, err
 	}
 	randNum := int(randNum64.Int64())
@@ -48,3 +252,34 @@ func getRandNum(maxNum int) (int, error) {
 	}
 	return randNum, nil
 }
+
+// Generate a random number between the given minimum and maximum value (both inclusive)
+func getRandNumBetween(minNum int, maxNum int) (int, error) {
+	if minNum > maxNum {
+		err := fmt.Errorf("provided minNum is bigger than maxNum: %v > %v", minNum, maxNum)
+		return 0, err
+	}
+	numRange := new(big.Int).Sub(big.NewInt(int64(maxNum)), big.NewInt(int64(minNum)))
+	numRange.Add(numRange, big.NewInt(1))
+	if !numRange.IsInt64() || int64(int(numRange.Int64())) != numRange.Int64() {
+		err := fmt.Errorf("range between minNum and maxNum is too big: %v to %v", minNum, maxNum)
+		return 0, err
+	}
+	randNum, err := getRandNum(int(numRange.Int64()))
+	if err != nil {
+		return 0, err
+	}
+	return minNum + randNum, nil
+}
+
+// Shuffle the given characters in place (Fisher-Yates shuffle)
+func shuffleRunes(runeSlice []rune) error {
+	for i := len(runeSlice) - 1; i > 0; i-- {
+		randNum, err := getRandNum(i + 1)
+		if err != nil {
+			return err
+		}
+		runeSlice[i], runeSlice[randNum] = runeSlice[randNum], runeSlice[i]
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+// Check if the last character of the given password exceeds the limit of identical
+// characters in a row (maxRepeat) or the limit of sequence length (maxSequence). A limit
+// of 0 disables the according check
+func exceedsCharLimits(pwRunes []rune, maxRepeat int, maxSequence int) bool {
+	if maxRepeat > 0 && trailingRepeatLength(pwRunes) > maxRepeat {
+		return true
+	}
+	if maxSequence > 0 && trailingSequenceLength(pwRunes) > maxSequence {
+		return true
+	}
+	return false
+}
+
+// Check if any character of the given password exceeds the limit of identical characters
+// in a row (maxRepeat) or the limit of sequence length (maxSequence)
+func hasCharLimitViolation(pwRunes []rune, maxRepeat int, maxSequence int) bool {
+	if maxRepeat <= 0 && maxSequence <= 0 {
+		return false
+	}
+	for i := 1; i <= len(pwRunes); i++ {
+		if exceedsCharLimits(pwRunes[:i], maxRepeat, maxSequence) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the amount of identical characters at the end of the given password
+func trailingRepeatLength(pwRunes []rune) int {
+	if len(pwRunes) == 0 {
+		return 0
+	}
+	lastChar := pwRunes[len(pwRunes)-1]
+	runLength := 1
+	for i := len(pwRunes) - 2; i >= 0 && pwRunes[i] == lastChar; i-- {
+		runLength++
+	}
+	return runLength
+}
+
+// Return the length of the ascending or descending character sequence at the end of the
+// given password
+func trailingSequenceLength(pwRunes []rune) int {
+	if len(pwRunes) < 2 {
+		return len(pwRunes)
+	}
+	seqDirection := pwRunes[len(pwRunes)-1] - pwRunes[len(pwRunes)-2]
+	if seqDirection != 1 && seqDirection != -1 {
+		return 1
+	}
+	runLength := 2
+	for i := len(pwRunes) - 2; i > 0 && pwRunes[i]-pwRunes[i-1] == seqDirection; i-- {
+		runLength++
+	}
+	return runLength
+}